@@ -0,0 +1,207 @@
+package diagnostics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"tim/token"
+	"unicode/utf8"
+)
+
+// Diagnostic describes a problem with a program in a way that can be shown alongside its source.
+// Kind says which stage found the problem, e.g. "parse error", Code identifies the problem
+// if it has a code, and Hint is an optional suggestion for how to fix it.
+type Diagnostic struct {
+	Kind    string
+	Code    string
+	Message string
+	Span    token.Span
+	Hint    string
+}
+
+// Error is implemented by the errors of each stage, so that the printer can render any of them
+type Error interface {
+	error
+	Diagnostic() Diagnostic
+}
+
+// Printer renders errors with the line of source they point at, underlined, like:
+//
+//	runtime error: division by zero
+//	 --> main.tim:2:6
+//	  |
+//	2 |   b: a / 0)
+//	  |      ^^^^^
+//
+// Errors that don't implement Error are printed as they are.
+type Printer struct {
+	// File is used for spans that don't name a file, such as those of lexer errors
+	File   string
+	Source string
+	// Color adds ANSI colours, leave it off for plain text, e.g. when writing to logs
+	Color bool
+}
+
+const (
+	bold  = "\x1b[1m"
+	red   = "\x1b[1;31m"
+	blue  = "\x1b[1;34m"
+	cyan  = "\x1b[1;36m"
+	reset = "\x1b[0m"
+)
+
+func (p *Printer) Print(w io.Writer, err error) {
+	var diagnosable Error
+	if !errors.As(err, &diagnosable) {
+		fmt.Fprintln(w, strings.TrimSuffix(err.Error(), "\n"))
+		return
+	}
+	p.PrintDiagnostic(w, diagnosable.Diagnostic())
+}
+
+func (p *Printer) PrintDiagnostic(w io.Writer, d Diagnostic) {
+	kind := d.Kind
+	if d.Code != "" {
+		kind += "[" + d.Code + "]"
+	}
+	fmt.Fprintf(w, "%s: %s\n", p.paint(red, kind), p.paint(bold, d.Message))
+	if !d.Span.IsValid() {
+		p.printHint(w, "", d.Hint)
+		return
+	}
+
+	file := d.Span.File
+	if file == "" {
+		file = p.File
+	}
+	gutter := strings.Repeat(" ", len(fmt.Sprint(d.Span.Start.Line)))
+	if file == "" {
+		fmt.Fprintf(w, "%s%s %d:%d\n", gutter, p.paint(blue, "-->"), d.Span.Start.Line, d.Span.Start.Column)
+	} else {
+		fmt.Fprintf(w, "%s%s %s:%d:%d\n", gutter, p.paint(blue, "-->"), file, d.Span.Start.Line, d.Span.Start.Column)
+	}
+
+	line, ok := sourceLine(p.Source, d.Span.Start.Line)
+	if !ok {
+		p.printHint(w, gutter, d.Hint)
+		return
+	}
+	fmt.Fprintf(w, "%s %s\n", gutter, p.paint(blue, "|"))
+	fmt.Fprintf(w, "%s %s %s\n", p.paint(blue, fmt.Sprint(d.Span.Start.Line)), p.paint(blue, "|"), line)
+	fmt.Fprintf(w, "%s %s %s%s\n", gutter, p.paint(blue, "|"), padding(line, d.Span.Start.Column), p.paint(red, underline(line, d.Span)))
+	p.printHint(w, gutter, d.Hint)
+}
+
+func (p *Printer) printHint(w io.Writer, gutter string, hint string) {
+	if hint != "" {
+		fmt.Fprintf(w, "%s %s %s\n", gutter, p.paint(blue, "="), p.paint(cyan, "hint:")+" "+hint)
+	}
+}
+
+func (p *Printer) paint(color string, text string) string {
+	if !p.Color {
+		return text
+	}
+	return color + text + reset
+}
+
+// line numbers count from 1
+func sourceLine(source string, number int) (string, bool) {
+	lines := strings.Split(source, "\n")
+	if number < 1 || number > len(lines) {
+		return "", false
+	}
+	return strings.TrimSuffix(lines[number-1], "\r"), true
+}
+
+// the space before the column, keeping any tabs so that the underline lines up with the source
+func padding(line string, column int) string {
+	var pad strings.Builder
+	for index, char := range []rune(line) {
+		if index >= column-1 {
+			break
+		}
+		if char == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteRune(' ')
+		}
+	}
+	return pad.String()
+}
+
+// a caret for every character of the span on its first line, and at least one so that
+// empty spans, such as the end of the input, can still be pointed at
+func underline(line string, span token.Span) string {
+	width := utf8.RuneCountInString(line) - (span.Start.Column - 1)
+	if span.End.Line == span.Start.Line {
+		width = span.End.Column - span.Start.Column
+	}
+	if width < 1 {
+		width = 1
+	}
+	return strings.Repeat("^", width)
+}
+
+// Suggest finds the candidate closest to name, for hints like "did you mean `fib`?".
+// Only candidates that are a small number of edits away are suggested.
+func Suggest(name string, candidates []string) (string, bool) {
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		distance := editDistance(name, candidate)
+		if bestDistance == -1 || distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	allowed := utf8.RuneCountInString(name) / 3
+	if allowed < 1 {
+		allowed = 1
+	}
+	if bestDistance == -1 || bestDistance > allowed {
+		return "", false
+	}
+	return best, true
+}
+
+// the number of insertions, deletions, substitutions and swaps of neighbouring runes it takes
+// to turn a into b, so that typos like "fbi" for "fib" count as one edit
+func editDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	distances := make([][]int, len(source)+1)
+	for i := range distances {
+		distances[i] = make([]int, len(target)+1)
+		distances[i][0] = i
+	}
+	for j := range distances[0] {
+		distances[0][j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			distances[i][j] = minInt(distances[i-1][j]+1, distances[i][j-1]+1, distances[i-1][j-1]+cost)
+			if i > 1 && j > 1 && source[i-1] == target[j-2] && source[i-2] == target[j-1] {
+				distances[i][j] = minInt(distances[i][j], distances[i-2][j-2]+1)
+			}
+		}
+	}
+	return distances[len(source)][len(target)]
+}
+
+func minInt(values ...int) int {
+	smallest := values[0]
+	for _, value := range values[1:] {
+		if value < smallest {
+			smallest = value
+		}
+	}
+	return smallest
+}
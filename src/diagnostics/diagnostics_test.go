@@ -0,0 +1,174 @@
+package diagnostics_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"tim/diagnostics"
+	"tim/lexer"
+	"tim/parser"
+	"tim/token"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrint(t *testing.T) {
+	cases := map[string]struct {
+		Source string
+		Output string
+	}{
+		"lex error": {
+			Source: "(a: 1,\n  b: 2 @ 3)",
+			Output: `lex error[T0001]: unsupported character '@'
+ --> main.tim:2:8
+  |
+2 |   b: 2 @ 3)
+  |        ^
+`,
+		},
+		"parse error": {
+			Source: "(a: 1 + )",
+			Output: `parse error[T0101]: expect expression.
+ --> main.tim:1:9
+  |
+1 | (a: 1 + )
+  |         ^
+`,
+		},
+		"underline the whole token": {
+			Source: "(a: 0xzz)",
+			Output: `lex error[T0007]: malformed number literal '0xzz'
+ --> main.tim:1:5
+  |
+1 | (a: 0xzz)
+  |     ^^^^
+`,
+		},
+		"end of input with hint": {
+			Source: "(a: 1",
+			Output: `parse error[T0102]: expected ')' after expression
+ --> main.tim:1:6
+  |
+1 | (a: 1
+  |      ^
+  = hint: the program ended early, check for a '(' or '{' that isn't closed
+`,
+		},
+		"tabs line up": {
+			Source: "(\ta: @)",
+			Output: "lex error[T0001]: unsupported character '@'\n --> main.tim:1:6\n  |\n1 | (\ta: @)\n  |  \t   ^\n",
+		},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := lexer.New(testcase.Source)
+			var err error
+			if len(l.Errors) > 0 {
+				err = l.Errors[0]
+			} else {
+				_, err = parser.Parse(l.Tokens)
+			}
+
+			printer := &diagnostics.Printer{File: "main.tim", Source: testcase.Source}
+			var output strings.Builder
+			printer.Print(&output, err)
+			assert.Equal(t, testcase.Output, output.String())
+		})
+	}
+}
+
+func TestPrintDiagnostic(t *testing.T) {
+	printer := &diagnostics.Printer{Source: "(fib: 1,\n  x: fbi + 1)"}
+	var output strings.Builder
+	printer.PrintDiagnostic(&output, diagnostics.Diagnostic{
+		Kind:    "runtime error",
+		Message: "Undefined variable 'fbi'.",
+		Span: token.Span{
+			File:  "fib.tim",
+			Start: token.Pos{Offset: 14, Line: 2, Column: 6},
+			End:   token.Pos{Offset: 17, Line: 2, Column: 9},
+		},
+		Hint: "did you mean `fib`?",
+	})
+	assert.Equal(t, `runtime error: Undefined variable 'fbi'.
+ --> fib.tim:2:6
+  |
+2 |   x: fbi + 1)
+  |      ^^^
+  = hint: did you mean `+"`fib`"+`?
+`, output.String())
+}
+
+func TestPrintColor(t *testing.T) {
+	printer := &diagnostics.Printer{Source: "@", Color: true}
+	var output strings.Builder
+	printer.Print(&output, lexer.New("@").Errors[0])
+	assert.Contains(t, output.String(), "\x1b[1;31mlex error[T0001]\x1b[0m")
+	assert.Contains(t, output.String(), "\x1b[1;31m^\x1b[0m")
+
+	printer.Color = false
+	output.Reset()
+	printer.Print(&output, lexer.New("@").Errors[0])
+	assert.NotContains(t, output.String(), "\x1b[")
+}
+
+func TestPrintPlainError(t *testing.T) {
+	printer := &diagnostics.Printer{}
+	var output strings.Builder
+	printer.Print(&output, errors.New("something went wrong"))
+	assert.Equal(t, "something went wrong\n", output.String())
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"fib", "print", "range", "total"}
+	cases := map[string]struct {
+		Name       string
+		Suggestion string
+	}{
+		"swapped letters": {Name: "fbi", Suggestion: "fib"},
+		"missing letter":  {Name: "prnt", Suggestion: "print"},
+		"extra letter":    {Name: "ranges", Suggestion: "range"},
+		"too different":   {Name: "count", Suggestion: ""},
+		"short names":     {Name: "x", Suggestion: ""},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			suggestion, _ := diagnostics.Suggest(testcase.Name, candidates)
+			assert.Equal(t, testcase.Suggestion, suggestion)
+		})
+	}
+}
+
+func TestJSONPrinter(t *testing.T) {
+	printer := &diagnostics.JSONPrinter{File: "<stdin>"}
+	var output strings.Builder
+	for _, err := range lexer.New("(a: 1,\n  b: 2 @ 3)").Errors {
+		printer.Print(&output, err)
+	}
+	printer.PrintDiagnostic(&output, diagnostics.Diagnostic{
+		Kind:    "runtime error",
+		Code:    "T0000",
+		Message: "something went wrong",
+		Span: token.Span{
+			File:  "main.tim",
+			Start: token.Pos{Offset: 0, Line: 1, Column: 1},
+			End:   token.Pos{Offset: 3, Line: 1, Column: 4},
+		},
+		Hint: "try again",
+	})
+	printer.Print(&output, errors.New("no position"))
+
+	assert.Equal(t, `{"severity":"error","code":"T0001","kind":"lex error","message":"unsupported character '@'","file":"<stdin>","span":{"start":{"offset":14,"line":2,"column":8},"end":{"offset":15,"line":2,"column":9}}}
+{"severity":"error","code":"T0000","kind":"runtime error","message":"something went wrong","file":"main.tim","span":{"start":{"offset":0,"line":1,"column":1},"end":{"offset":3,"line":1,"column":4}},"hint":"try again"}
+{"severity":"error","code":"","kind":"","message":"no position","file":"<stdin>","span":null}
+`, output.String())
+}
+
+func TestPrintCode(t *testing.T) {
+	printer := &diagnostics.Printer{}
+	var output strings.Builder
+	printer.PrintDiagnostic(&output, diagnostics.Diagnostic{Kind: "runtime error", Code: "T2003", Message: "division by zero"})
+	assert.Equal(t, "runtime error[T2003]: division by zero\n", output.String())
+}
@@ -0,0 +1,93 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"tim/token"
+)
+
+// JSONPrinter writes each error as a JSON object on its own line, for editors and CI
+// to read. Every error is reported with the "error" severity.
+type JSONPrinter struct {
+	// File fills the "file" field of errors that don't carry one themselves, i.e. lexer
+	// errors and errors without a span
+	File string
+}
+
+type jsonDiagnostic struct {
+	Severity string    `json:"severity"`
+	Code     string    `json:"code"`
+	Kind     string    `json:"kind"`
+	Message  string    `json:"message"`
+	File     string    `json:"file"`
+	Span     *jsonSpan `json:"span"`
+	Hint     string    `json:"hint,omitempty"`
+}
+
+type jsonSpan struct {
+	Start jsonPos `json:"start"`
+	End   jsonPos `json:"end"`
+}
+
+type jsonPos struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p *JSONPrinter) Print(w io.Writer, err error) {
+	var diagnosable Error
+	if !errors.As(err, &diagnosable) {
+		p.write(w, jsonDiagnostic{
+			Severity: "error",
+			Message:  strings.TrimSuffix(err.Error(), "\n"),
+			File:     p.File,
+		})
+		return
+	}
+	p.PrintDiagnostic(w, diagnosable.Diagnostic())
+}
+
+func (p *JSONPrinter) PrintDiagnostic(w io.Writer, d Diagnostic) {
+	file := d.Span.File
+	if file == "" {
+		file = p.File
+	}
+
+	// errors without a span have a null span rather than one pointing at line 0
+	var span *jsonSpan
+	if d.Span.IsValid() {
+		span = &jsonSpan{
+			Start: toJSONPos(d.Span.Start),
+			End:   toJSONPos(d.Span.End),
+		}
+	}
+
+	p.write(w, jsonDiagnostic{
+		Severity: "error",
+		Code:     d.Code,
+		Kind:     d.Kind,
+		Message:  d.Message,
+		File:     file,
+		Span:     span,
+		Hint:     d.Hint,
+	})
+}
+
+func (p *JSONPrinter) write(w io.Writer, d jsonDiagnostic) {
+	encoder := json.NewEncoder(w)
+	// file names like <stdin> are kept readable
+	encoder.SetEscapeHTML(false)
+	// the fields are all strings and numbers, so encoding can't fail
+	_ = encoder.Encode(d)
+}
+
+func toJSONPos(pos token.Pos) jsonPos {
+	return jsonPos{
+		Offset: pos.Offset,
+		Line:   pos.Line,
+		Column: pos.Column,
+	}
+}
@@ -17,27 +17,63 @@ type Environment struct {
 	Enclosing *Environment
 }
 
+// Define binds the name in this scope, shadowing any binding of the same name in an enclosing scope
 func (e *Environment) Define(name string, value interface{}) {
 	e.Values[name] = value
+}
 
-	// only modify global if exists
-	if e.Enclosing != nil {
-		if _, ok := e.Enclosing.Values[name]; ok {
-			e.Enclosing.Values[name] = value
+// Get looks the name up in this scope, then in each enclosing scope in turn
+func (e *Environment) Get(token token.Token) (interface{}, error) {
+	for environment := e; environment != nil; environment = environment.Enclosing {
+		if value, ok := environment.Values[token.Text]; ok {
+			return value, nil
 		}
 	}
+
+	return nil, errors.NewRuntimeError(errors.UndefinedVariable, "Undefined variable '"+token.Text+"'.")
 }
 
-func (e *Environment) Get(token token.Token) (interface{}, error) {
-	if _, ok := e.Values[token.Text]; ok {
-		return e.Values[token.Text], nil
+// Assign updates the binding in the nearest scope that defines the name
+func (e *Environment) Assign(token token.Token, value interface{}) error {
+	for environment := e; environment != nil; environment = environment.Enclosing {
+		if _, ok := environment.Values[token.Text]; ok {
+			environment.Values[token.Text] = value
+			return nil
+		}
 	}
 
-	if e.Enclosing != nil {
-		if _, ok := e.Enclosing.Values[token.Text]; ok {
-			return e.Enclosing.Values[token.Text], nil
+	return errors.NewRuntimeError(errors.UndefinedVariable, "Undefined variable '"+token.Text+"'.")
+}
+
+// Names lists every name visible from this scope
+func (e *Environment) Names() []string {
+	var names []string
+	for environment := e; environment != nil; environment = environment.Enclosing {
+		for name := range environment.Values {
+			names = append(names, name)
 		}
 	}
+	return names
+}
 
-	return nil, errors.NewRuntimeError("Undefined variable '" + token.Text + "'.")
+// GetAt looks the name up in the environment the given number of scopes up, as recorded by the resolver
+func (e *Environment) GetAt(distance int, token token.Token) (interface{}, error) {
+	if value, ok := e.ancestor(distance).Values[token.Text]; ok {
+		return value, nil
+	}
+
+	return nil, errors.NewRuntimeError(errors.UndefinedVariable, "Undefined variable '"+token.Text+"'.")
+}
+
+// AssignAt updates the binding in the environment the given number of scopes up, as recorded by the resolver
+func (e *Environment) AssignAt(distance int, token token.Token, value interface{}) {
+	e.ancestor(distance).Values[token.Text] = value
+}
+
+func (e *Environment) ancestor(distance int) *Environment {
+	environment := e
+	for i := 0; i < distance; i++ {
+		environment = environment.Enclosing
+	}
+	return environment
 }
@@ -0,0 +1,343 @@
+package errors
+
+import "sort"
+
+// Code identifies a kind of error, so that it can be looked up with `tim explain`.
+// Codes are grouped by the stage or part of the language they come from:
+//
+//	T00xx  lexing
+//	T01xx  parsing
+//	T1xxx  variables and scope
+//	T2xxx  operators
+//	T3xxx  calling functions
+//	T9xxx  bugs in the interpreter itself
+type Code string
+
+const (
+	UnsupportedCharacter      Code = "T0001"
+	UnterminatedString        Code = "T0002"
+	UnterminatedInterpolation Code = "T0003"
+	UnknownEscape             Code = "T0004"
+	InvalidUnicodeEscape      Code = "T0005"
+	UnterminatedComment       Code = "T0006"
+	MalformedNumber           Code = "T0007"
+	NumberOutOfRange          Code = "T0008"
+	LeadingZeros              Code = "T0009"
+
+	ExpectedExpression      Code = "T0101"
+	ExpectedToken           Code = "T0102"
+	InvalidAssignmentTarget Code = "T0103"
+	InvalidArgumentName     Code = "T0104"
+
+	UndefinedVariable     Code = "T1001"
+	ReadInOwnInitializer  Code = "T1002"
+	ReturnOutsideFunction Code = "T1003"
+
+	OperandsMustBeNumber   Code = "T2001"
+	OperandMustBeNumber    Code = "T2002"
+	DivisionByZero         Code = "T2003"
+	OperandsMustBeInteger  Code = "T2004"
+	OperandMustBeInteger   Code = "T2005"
+	NegativeShiftCount     Code = "T2006"
+	ConditionMustBeBoolean Code = "T2007"
+
+	NotCallable            Code = "T3001"
+	WrongNumberOfArguments Code = "T3002"
+	ArgumentMustBeFunction Code = "T3003"
+	OperandsMustBeString   Code = "T3004"
+	UnknownLocale          Code = "T3005"
+
+	InternalError Code = "T9001"
+)
+
+type explanation struct {
+	title string
+	text  string
+}
+
+var catalogue = map[Code]explanation{
+	UnsupportedCharacter: {
+		title: "unsupported character",
+		text: `The program contains a character that isn't part of any token, such as '@' or '#'
+outside of a string.
+
+    (a: 1 @ 2)
+
+Remove the character, or put it inside a string if it's meant to be text.`,
+	},
+	UnterminatedString: {
+		title: "unterminated string",
+		text: `A string was opened with a quote but the program ended before the closing quote.
+
+    (greeting: "hello)
+
+Add the closing quote. Strings can span several lines, so the missing quote may be some
+way above where the program ends.`,
+	},
+	UnterminatedInterpolation: {
+		title: "unterminated string interpolation",
+		text: `A string contains a '${' that is never closed with a '}'.
+
+    ("total: ${total)
+
+Close the interpolation with '}' before the end of the string.`,
+	},
+	UnknownEscape: {
+		title: "unknown escape sequence",
+		text: `A backslash in a string is followed by a character that doesn't form an escape.
+The escapes Tim understands are \n, \t, \\, \", \', \$ and \u{...}.
+
+    ("C:\Users").print()
+
+Write '\\' for a literal backslash.`,
+	},
+	InvalidUnicodeEscape: {
+		title: "invalid unicode escape",
+		text: `A \u escape must be followed by the hex digits of a code point between braces, and
+the code point must be a valid unicode character.
+
+    ("\u{110000}").print()
+
+Check the braces are there and that the value is at most 10FFFF and isn't a surrogate.`,
+	},
+	UnterminatedComment: {
+		title: "unterminated block comment",
+		text: `A block comment was opened with '/*' but never closed with '*/'.
+
+    /* a comment
+    (a: 1)
+
+Close the comment with '*/'. Everything after the '/*' is treated as part of it.`,
+	},
+	MalformedNumber: {
+		title: "malformed number literal",
+		text: `A number literal couldn't be read, usually because it holds digits that aren't
+allowed by its base or is missing digits after a prefix or exponent.
+
+    (a: 0xzz, b: 0b102, c: 1e)
+
+Hex literals use 0-9 and a-f, binary literals 0 and 1 and octal literals 0-7.`,
+	},
+	NumberOutOfRange: {
+		title: "number literal out of range",
+		text: `A number literal is too large to be represented. Integers must fit in 64 bits and
+floats must be finite.
+
+    (a: 99999999999999999999)
+
+Use a smaller value, or a float if precision can be given up.`,
+	},
+	LeadingZeros: {
+		title: "leading zeros in number literal",
+		text: `A decimal number starts with a zero, which is ambiguous because some languages read
+it as octal.
+
+    (permissions: 0755)
+
+Remove the leading zeros, or use the 0o prefix for octal, e.g. 0o755.`,
+	},
+	ExpectedExpression: {
+		title: "expected expression",
+		text: `The parser reached a point where a value was needed but found something else, often
+a closing bracket after an operator.
+
+    (a: 1 + )
+
+Finish the expression, or remove the operator.`,
+	},
+	ExpectedToken: {
+		title: "expected token",
+		text: `A particular token was needed, such as the ')' that closes a list or the '=>' after
+the condition of a branch, but something else was found.
+
+    (a: 1
+
+The message says which token was expected. If the program ended early, check for a '(' or
+'{' that isn't closed.`,
+	},
+	InvalidAssignmentTarget: {
+		title: "invalid assignment target",
+		text: `The left of an assignment must be the name of a variable.
+
+    (a + 1 = 2)
+
+Assign to a name instead, e.g. (b: a + 1).`,
+	},
+	InvalidArgumentName: {
+		title: "function arguments must be identifiers",
+		text: `The arguments in a function declaration must all be names.
+
+    (add: (1, b) => { >> b })
+
+Give each argument a name, e.g. (a, b).`,
+	},
+	UndefinedVariable: {
+		title: "undefined variable",
+		text: `A variable was used or assigned to before it was declared, or isn't visible from
+where it's used.
+
+    (a: b + 1)
+
+Declare the variable first, e.g. (b: 1, a: b + 1), and check its spelling. Variables
+declared inside a list or function can't be used outside of it.`,
+	},
+	ReadInOwnInitializer: {
+		title: "local variable read in its own initializer",
+		text: `A local variable was used in the expression that gives it its value, before it has
+one.
+
+    (a: 1, b: (a: a + 1))
+
+Give the inner variable a different name, or declare it before using it.`,
+	},
+	ReturnOutsideFunction: {
+		title: "return outside of a function",
+		text: `A return ('>>') was found outside of a function body, where there's nothing to
+return from.
+
+    (>> 1)
+
+Move the return into a function, or remove it.`,
+	},
+	OperandsMustBeNumber: {
+		title: "operands must be numbers",
+		text: `An arithmetic or comparison operator was given a value that isn't a number.
+
+    (a: "1" - 2)
+
+Check the types of both sides. '+' also accepts two strings, which it joins, and <, <=, >
+and >= compare two strings by code point.`,
+	},
+	OperandMustBeNumber: {
+		title: "operand must be a number",
+		text: `A unary operator such as '-' was given a value that isn't a number.
+
+    (a: -"1")
+
+Check the type of the value the operator is applied to.`,
+	},
+	DivisionByZero: {
+		title: "division by zero",
+		text: `A number was divided by zero, which has no result.
+
+    (a: 1, b: 0, c: a / b)
+
+Check that the divisor isn't zero before dividing, e.g. with a conditional.`,
+	},
+	OperandsMustBeInteger: {
+		title: "operands must be integers",
+		text: `A bitwise or shift operator was given a value that isn't an integer. The operators
+&, |, ^, << and >>> only work on integers, not floats.
+
+    (a: 6.0 & 3)
+
+Check the types of both sides, and use ~/ rather than / to keep integers whole when
+dividing.`,
+	},
+	OperandMustBeInteger: {
+		title: "operand must be an integer",
+		text: `The bitwise not operator ~ was given a value that isn't an integer.
+
+    (a: ~1.5)
+
+Check the type of the value the operator is applied to.`,
+	},
+	NegativeShiftCount: {
+		title: "negative shift count",
+		text: `The right side of a << or >>> is the number of bits to shift by, which can't be negative.
+
+    (a: 0 - 1, b: 1 << a)
+
+Shift the other way instead, e.g. 1 >>> 1 rather than 1 << -1.`,
+	},
+	ConditionMustBeBoolean: {
+		title: "condition must be a boolean",
+		text: `In strict mode, the conditions of a conditional and the operands of !, && and || must be
+true or false. Outside of strict mode any value can be used, and nil, false, zero, the
+empty string and the empty list count as false.
+
+    (count: 0)
+    ?((count) => ("none").print())
+
+Compare the value instead, e.g. (count == 0).`,
+	},
+	NotCallable: {
+		title: "can only call functions",
+		text: `A value that isn't a function was called.
+
+    (a: 1)
+    (2).a()
+
+Check that the name refers to a function.`,
+	},
+	WrongNumberOfArguments: {
+		title: "wrong number of arguments",
+		text: `A function was called with a different number of arguments than it accepts.
+
+    (add: (a, b) => { >> a + b })
+    (1).call(add)
+
+Pass one value for each of the function's arguments.`,
+	},
+	ArgumentMustBeFunction: {
+		title: "argument must be a function",
+		text: `A built-in function that calls one of its arguments, such as call, was given
+something that isn't a function.
+
+    (1).call(2)
+
+Pass a function instead.`,
+	},
+	OperandsMustBeString: {
+		title: "operands must be strings",
+		text: `A built-in function that works on strings, such as collate, was given something that
+isn't a string.
+
+    (1, "a").collate()
+
+Pass strings instead, e.g. ("1", "a").collate().`,
+	},
+	UnknownLocale: {
+		title: "unknown locale",
+		text: `The locale passed to collate isn't a valid BCP 47 language tag.
+
+    ("a", "b").collate("en_GB!")
+
+Use a tag such as "en", "sv" or "de-CH", or leave it out to use the root order.`,
+	},
+	InternalError: {
+		title: "internal error",
+		text: `Something went wrong inside the interpreter itself. This is a bug in Tim rather than
+in your program.
+
+Please report it along with the program that caused it.`,
+	},
+}
+
+// LookupCode returns the code with the given name, e.g. "T2003", if there is one
+func LookupCode(name string) (Code, bool) {
+	_, ok := catalogue[Code(name)]
+	return Code(name), ok
+}
+
+// Codes lists every code in order
+func Codes() []Code {
+	codes := make([]Code, 0, len(catalogue))
+	for code := range catalogue {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i] < codes[j]
+	})
+	return codes
+}
+
+// Title is a short description of the code, e.g. "division by zero"
+func (c Code) Title() string {
+	return catalogue[c].title
+}
+
+// Explanation describes the code at length, with an example and how to fix it
+func (c Code) Explanation() string {
+	return catalogue[c].text
+}
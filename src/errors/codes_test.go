@@ -0,0 +1,86 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"strings"
+	"testing"
+	"tim/errors"
+	"tim/interpreter"
+	"tim/lexer"
+	"tim/parser"
+	"tim/resolver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodesAreExplained(t *testing.T) {
+	for _, code := range errors.Codes() {
+		t.Run(string(code), func(t *testing.T) {
+			assert.Regexp(t, `^T\d{4}$`, string(code))
+			assert.NotEmpty(t, code.Title())
+			assert.NotEmpty(t, code.Explanation())
+			assert.False(t, strings.HasSuffix(code.Explanation(), "\n"))
+		})
+	}
+}
+
+func TestLookupCode(t *testing.T) {
+	code, ok := errors.LookupCode("T2003")
+	assert.True(t, ok)
+	assert.Equal(t, errors.DivisionByZero, code)
+	assert.Equal(t, "division by zero", code.Title())
+
+	_, ok = errors.LookupCode("T9999")
+	assert.False(t, ok)
+}
+
+func TestRuntimeErrorDiagnostic(t *testing.T) {
+	err := errors.NewRuntimeError(errors.UndefinedVariable, "Undefined variable 'x'.")
+	assert.Equal(t, errors.UndefinedVariable, err.ErrorCode())
+	assert.Equal(t, "T1001", err.Diagnostic().Code)
+}
+
+// the example in each explanation should report the code it explains
+func TestExamplesReportTheirCode(t *testing.T) {
+	for _, code := range errors.Codes() {
+		if code == errors.InternalError {
+			continue
+		}
+		t.Run(string(code), func(t *testing.T) {
+			var example []string
+			for _, line := range strings.Split(code.Explanation(), "\n") {
+				if strings.HasPrefix(line, "    ") {
+					example = append(example, strings.TrimPrefix(line, "    "))
+				}
+			}
+			assert.NotEmpty(t, example)
+			assert.Equal(t, code, firstErrorCode(strings.Join(example, "\n")))
+		})
+	}
+}
+
+func firstErrorCode(source string) errors.Code {
+	var err error
+	l := lexer.New(source)
+	p := parser.New(l.Tokens)
+	statements := p.Parse()
+	statements, resolveErrs := resolver.Resolve(statements)
+	switch {
+	case len(l.Errors) > 0:
+		err = l.Errors[0]
+	case len(p.Errors) > 0:
+		err = p.Errors[0]
+	case len(resolveErrs) > 0:
+		err = resolveErrs[0]
+	default:
+		i := interpreter.New()
+		i.Strict = true
+		_, err = i.Interpret(statements)
+	}
+
+	var coded errors.Error
+	if !stderrors.As(err, &coded) {
+		return ""
+	}
+	return coded.ErrorCode()
+}
@@ -1,18 +1,55 @@
 package errors
 
-const (
-	OperandsMustBeNumber = "operands must be number"
-	DivisionByZero       = "division by zero"
+import (
+	"fmt"
+	"tim/diagnostics"
+	"tim/token"
 )
 
+// Error is implemented by the errors of every stage: *lexer.LexError, *parser.ParseError,
+// *resolver.ResolveError and *RuntimeError. Each has a Code that `tim explain` can describe.
+type Error interface {
+	diagnostics.Error
+	ErrorCode() Code
+}
+
+var _ Error = (*RuntimeError)(nil)
+
+// RuntimeError is raised while interpreting. Span is the expression that failed, and is
+// left as the zero Span by code that doesn't know where it was called from, such as the
+// operator helpers, until the interpreter fills it in. Hint is an optional suggestion.
 type RuntimeError struct {
-	message string
+	Code    Code
+	Message string
+	Span    token.Span
+	Hint    string
 }
 
 func (r RuntimeError) Error() string {
-	return r.message
+	if !r.Span.IsValid() {
+		return r.Message
+	}
+	return fmt.Sprintf("[line %d] Error: %s", r.Span.Start.Line, r.Message)
+}
+
+func (r RuntimeError) ErrorCode() Code {
+	return r.Code
+}
+
+func (r RuntimeError) Diagnostic() diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Kind:    "runtime error",
+		Code:    string(r.Code),
+		Message: r.Message,
+		Span:    r.Span,
+		Hint:    r.Hint,
+	}
+}
+
+func NewRuntimeError(code Code, msg string) *RuntimeError {
+	return &RuntimeError{Code: code, Message: msg}
 }
 
-func NewRuntimeError(msg string) *RuntimeError {
-	return &RuntimeError{message: msg}
+func NewRuntimeErrorAt(span token.Span, code Code, msg string) *RuntimeError {
+	return &RuntimeError{Code: code, Message: msg, Span: span}
 }
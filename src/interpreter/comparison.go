@@ -2,13 +2,19 @@ package interpreter
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"strings"
+	"sync"
 	"tim/errors"
+
+	textcollate "golang.org/x/text/collate"
+	"golang.org/x/text/language"
 )
 
 func subtract(left, right interface{}) interface{} {
 	if isNaN(left) || isNaN(right) {
-		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber))
+		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber, "operands must be numbers"))
 	}
 
 	if isInt(left) && isInt(right) {
@@ -44,11 +50,11 @@ func add(left, right interface{}) interface{} {
 
 func divide(left, right interface{}) interface{} {
 	if isZero(left) || isZero(right) {
-		panic(errors.NewRuntimeError(errors.DivisionByZero))
+		panic(errors.NewRuntimeError(errors.DivisionByZero, "division by zero"))
 	}
 
 	if isNaN(left) || isNaN(right) {
-		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber))
+		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber, "operands must be numbers"))
 	}
 
 	if isInt(left, right) {
@@ -67,7 +73,7 @@ func divide(left, right interface{}) interface{} {
 
 func multiply(left, right interface{}) interface{} {
 	if isNaN(left) || isNaN(right) {
-		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber))
+		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber, "operands must be numbers"))
 	}
 
 	if isInt(left, right) {
@@ -84,9 +90,193 @@ func multiply(left, right interface{}) interface{} {
 	return nil
 }
 
+// ints stay ints and floats stay floats
+func negate(right interface{}) interface{} {
+	switch value := right.(type) {
+	case int:
+		return -value
+	case float64:
+		return -value
+	case float32:
+		return -value
+	}
+	panic(errors.NewRuntimeError(errors.OperandMustBeNumber, "operand must be a number"))
+}
+
+// + doesn't change a number, but still checks that it has one
+func unaryPlus(right interface{}) interface{} {
+	switch right.(type) {
+	case int, float64, float32:
+		return right
+	}
+	panic(errors.NewRuntimeError(errors.OperandMustBeNumber, "operand must be a number"))
+}
+
+// the remainder takes the sign of the divisor, so that it pairs with floorDivide:
+// left == (left ~/ right) * right + left % right
+func modulo(left, right interface{}) interface{} {
+	if isNaN(left) || isNaN(right) {
+		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber, "operands must be numbers"))
+	}
+
+	if isZero(right) {
+		panic(errors.NewRuntimeError(errors.DivisionByZero, "division by zero"))
+	}
+
+	if isInt(left, right) {
+		remainder := left.(int) % right.(int)
+		if remainder != 0 && (remainder < 0) != (right.(int) < 0) {
+			remainder += right.(int)
+		}
+		return remainder
+	}
+
+	leftFloat, _ := toFloat(left)
+	rightFloat, _ := toFloat(right)
+
+	remainder := math.Mod(leftFloat, rightFloat)
+	if remainder != 0 && (remainder < 0) != (rightFloat < 0) {
+		remainder += rightFloat
+	}
+	return remainder
+}
+
+// rounds the quotient down rather than towards zero, and stays a float if either side is one
+func floorDivide(left, right interface{}) interface{} {
+	if isNaN(left) || isNaN(right) {
+		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber, "operands must be numbers"))
+	}
+
+	if isZero(right) {
+		panic(errors.NewRuntimeError(errors.DivisionByZero, "division by zero"))
+	}
+
+	if isInt(left, right) {
+		quotient := left.(int) / right.(int)
+		if left.(int)%right.(int) != 0 && (left.(int) < 0) != (right.(int) < 0) {
+			quotient--
+		}
+		return quotient
+	}
+
+	leftFloat, _ := toFloat(left)
+	rightFloat, _ := toFloat(right)
+
+	return math.Floor(leftFloat / rightFloat)
+}
+
+// an int raised to a non-negative int stays an int unless the result doesn't fit in one,
+// anything else is a float
+func power(left, right interface{}) interface{} {
+	if isNaN(left) || isNaN(right) {
+		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber, "operands must be numbers"))
+	}
+
+	leftFloat, _ := toFloat(left)
+	rightFloat, _ := toFloat(right)
+
+	if leftFloat == 0 && rightFloat < 0 {
+		panic(errors.NewRuntimeError(errors.DivisionByZero, "division by zero"))
+	}
+
+	if isInt(left, right) && right.(int) >= 0 {
+		if result, ok := intPower(left.(int), right.(int)); ok {
+			return result
+		}
+	}
+
+	return math.Pow(leftFloat, rightFloat)
+}
+
+// exponentiation by squaring, which fails rather than wrapping around if the result overflows
+func intPower(base, exponent int) (int, bool) {
+	result, ok := 1, true
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			if result, ok = multiplyInts(result, base); !ok {
+				return 0, false
+			}
+		}
+		exponent >>= 1
+		// the last square isn't needed, and could overflow when the result doesn't
+		if exponent > 0 {
+			if base, ok = multiplyInts(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
+
+func multiplyInts(left, right int) (int, bool) {
+	if left == 0 || right == 0 {
+		return 0, true
+	}
+	product := left * right
+	if product/right != left || (left == -1 && right == math.MinInt) || (right == -1 && left == math.MinInt) {
+		return 0, false
+	}
+	return product, true
+}
+
+// the bitwise and shift operators only work on ints, and treat them as 64-bit two's complement
+func bitwiseAnd(left, right interface{}) interface{} {
+	if !isInt(left, right) {
+		panic(errors.NewRuntimeError(errors.OperandsMustBeInteger, "operands must be integers"))
+	}
+	return left.(int) & right.(int)
+}
+
+func bitwiseOr(left, right interface{}) interface{} {
+	if !isInt(left, right) {
+		panic(errors.NewRuntimeError(errors.OperandsMustBeInteger, "operands must be integers"))
+	}
+	return left.(int) | right.(int)
+}
+
+func bitwiseXor(left, right interface{}) interface{} {
+	if !isInt(left, right) {
+		panic(errors.NewRuntimeError(errors.OperandsMustBeInteger, "operands must be integers"))
+	}
+	return left.(int) ^ right.(int)
+}
+
+func bitwiseNot(right interface{}) interface{} {
+	if !isInt(right) {
+		panic(errors.NewRuntimeError(errors.OperandMustBeInteger, "operand must be an integer"))
+	}
+	return ^right.(int)
+}
+
+func shiftLeft(left, right interface{}) interface{} {
+	if !isInt(left, right) {
+		panic(errors.NewRuntimeError(errors.OperandsMustBeInteger, "operands must be integers"))
+	}
+	if right.(int) < 0 {
+		panic(errors.NewRuntimeError(errors.NegativeShiftCount, "negative shift count"))
+	}
+	return left.(int) << right.(int)
+}
+
+// >>> shifts in zeros, as it does in Java and JavaScript, so negative numbers become positive
+func shiftRight(left, right interface{}) interface{} {
+	if !isInt(left, right) {
+		panic(errors.NewRuntimeError(errors.OperandsMustBeInteger, "operands must be integers"))
+	}
+	if right.(int) < 0 {
+		panic(errors.NewRuntimeError(errors.NegativeShiftCount, "negative shift count"))
+	}
+	return int(uint(left.(int)) >> right.(int))
+}
+
+// strings are compared by code point, which is the order Go compares their UTF-8 bytes in
 func greaterThan(left, right interface{}) bool {
+	if isString(left, right) {
+		return left.(string) > right.(string)
+	}
+
 	if isNaN(left) || isNaN(right) {
-		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber))
+		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber, "operands must be two numbers or two strings"))
 	}
 
 	if isInt(left, right) {
@@ -104,8 +294,12 @@ func greaterThan(left, right interface{}) bool {
 }
 
 func greaterThanOrEqual(left, right interface{}) bool {
+	if isString(left, right) {
+		return left.(string) >= right.(string)
+	}
+
 	if isNaN(left) || isNaN(right) {
-		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber))
+		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber, "operands must be two numbers or two strings"))
 	}
 
 	if isInt(left, right) {
@@ -123,8 +317,12 @@ func greaterThanOrEqual(left, right interface{}) bool {
 }
 
 func lessThan(left, right interface{}) bool {
+	if isString(left, right) {
+		return left.(string) < right.(string)
+	}
+
 	if isNaN(left) || isNaN(right) {
-		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber))
+		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber, "operands must be two numbers or two strings"))
 	}
 
 	if isInt(left, right) {
@@ -142,8 +340,12 @@ func lessThan(left, right interface{}) bool {
 }
 
 func lessThanOrEqual(left, right interface{}) bool {
+	if isString(left, right) {
+		return left.(string) <= right.(string)
+	}
+
 	if isNaN(left) || isNaN(right) {
-		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber))
+		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber, "operands must be two numbers or two strings"))
 	}
 
 	if isInt(left, right) {
@@ -160,6 +362,39 @@ func lessThanOrEqual(left, right interface{}) bool {
 	return false
 }
 
+// collate orders strings the way people read them in the given locale, using the Unicode
+// collation algorithm with runs of digits compared by their value, so "file2" comes before
+// "file10" and "éclair" before "zebra". Strings the collator can't tell apart fall back to
+// code point order, so that only equal strings collate as equal. It returns -1, 0 or 1,
+// like strings.Compare.
+func collate(left, right string, locale language.Tag) int {
+	if order := collators.compare(left, right, locale); order != 0 {
+		return order
+	}
+	return strings.Compare(left, right)
+}
+
+// building a collator loads its tables, so one is kept for each locale that's been used
+var collators = &collatorCache{byLocale: make(map[language.Tag]*textcollate.Collator)}
+
+// a collator keeps state between comparisons, so the lock is held while one is in use
+type collatorCache struct {
+	mu       sync.Mutex
+	byLocale map[language.Tag]*textcollate.Collator
+}
+
+func (c *collatorCache) compare(left, right string, locale language.Tag) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	collator, ok := c.byLocale[locale]
+	if !ok {
+		collator = textcollate.New(locale, textcollate.Numeric)
+		c.byLocale[locale] = collator
+	}
+	return collator.CompareString(left, right)
+}
+
 func equal(left, right interface{}) bool {
 	if isNumber(left, right) {
 		leftFloat, _ := toFloat(left)
@@ -0,0 +1,54 @@
+package interpreter
+
+import (
+	"fmt"
+	"tim/env"
+	"tim/errors"
+	"tim/tree"
+)
+
+// Function is a user defined function, called with the values of the list it's attached to.
+// Closure is the environment the function was declared in.
+type Function struct {
+	Declaration tree.FuncStmt
+	Closure     *env.Environment
+}
+
+func (f Function) Arity() int {
+	return len(f.Declaration.Arguments)
+}
+
+func (f Function) Call(i *Interpreter, caller interface{}, _ []interface{}) interface{} {
+	values := callerValues(caller)
+	if len(values) != f.Arity() {
+		panic(errors.NewRuntimeError(errors.WrongNumberOfArguments, fmt.Sprintf("expected %d arguments but got %d", f.Arity(), len(values))))
+	}
+
+	environment := env.NewEnvironment(f.Closure)
+	for index, argument := range f.Declaration.Arguments {
+		name := argument.(tree.ExpressionStmt).Expr.(tree.Variable).Name
+		environment.Define(name.Text, values[index])
+	}
+
+	return i.executeFunctionBody(f.Declaration.Body, environment)
+}
+
+func (f Function) String() string {
+	return "<closure>"
+}
+
+// the values of a caller list become the function's arguments, anything else is a single argument
+func callerValues(caller interface{}) []interface{} {
+	switch t := caller.(type) {
+	case *OrderedMap:
+		values := make([]interface{}, 0, t.Len())
+		for el := t.Front(); el != nil; el = el.Next() {
+			values = append(values, el.Value)
+		}
+		return values
+	case nil:
+		return nil
+	default:
+		return []interface{}{t}
+	}
+}
@@ -3,6 +3,8 @@ package interpreter
 import (
 	"fmt"
 	"tim/errors"
+
+	"golang.org/x/text/language"
 )
 
 type Print struct {
@@ -13,7 +15,7 @@ func (p Print) Arity() int {
 }
 
 func (p Print) Call(i *Interpreter, caller interface{}, _ []interface{}) interface{} {
-	fmt.Printf("%s\n", printValue(caller))
+	fmt.Printf("%s\n", PrintValue(caller))
 	return nil
 }
 
@@ -51,10 +53,21 @@ func (r Range) Arity() int {
 }
 
 func (r Range) Call(i *Interpreter, caller interface{}, arguments []interface{}) interface{} {
-	if len(arguments) > 2 {
-		panic(errors.NewRuntimeError("maximum of 2 arguments allowed for method 'range'"))
+	if len(arguments) != 2 {
+		panic(errors.NewRuntimeError(errors.WrongNumberOfArguments, "method 'range' expects 2 arguments"))
+	}
+
+	min, max := arguments[0], arguments[1]
+	if isNaN(min, max) {
+		panic(errors.NewRuntimeError(errors.OperandsMustBeNumber, "arguments to method 'range' must be numbers"))
 	}
-	return makeRange(arguments[0].(float64), arguments[1].(float64))
+	// a range of ints holds ints, otherwise it's a range of floats
+	if isInt(min, max) {
+		return makeIntRange(min.(int), max.(int))
+	}
+	minFloat, _ := toFloat(min)
+	maxFloat, _ := toFloat(max)
+	return makeRange(minFloat, maxFloat)
 }
 
 type Get struct {
@@ -66,7 +79,7 @@ func (g Get) Arity() int {
 
 func (g Get) Call(i *Interpreter, caller interface{}, arguments []interface{}) interface{} {
 	if len(arguments) > 1 {
-		panic(errors.NewRuntimeError("maximum of 1 argument allowed for method 'get'"))
+		panic(errors.NewRuntimeError(errors.WrongNumberOfArguments, "maximum of 1 argument allowed for method 'get'"))
 	}
 
 	selector := arguments[0]
@@ -98,6 +111,83 @@ func (g Get) Call(i *Interpreter, caller interface{}, arguments []interface{}) i
 	return nil
 }
 
+type Call struct {
+}
+
+func (c Call) Arity() int {
+	return 1
+}
+
+func (c Call) Call(i *Interpreter, caller interface{}, arguments []interface{}) interface{} {
+	if len(arguments) != 1 {
+		panic(errors.NewRuntimeError(errors.WrongNumberOfArguments, "method 'call' expects 1 argument"))
+	}
+
+	function, ok := arguments[0].(Callable)
+	if !ok {
+		panic(errors.NewRuntimeError(errors.ArgumentMustBeFunction, "argument to method 'call' must be a function"))
+	}
+
+	return function.Call(i, caller, nil)
+}
+
+func (c Call) String() string {
+	return "<native fn>"
+}
+
+// Collate compares the two strings in the list it's called on as people would order them,
+// e.g. ("file10", "file2").collate() is 1. It takes an optional locale such as "sv", and
+// otherwise uses the root collation order.
+type Collate struct {
+}
+
+func (c Collate) Arity() int {
+	return 1
+}
+
+func (c Collate) Call(i *Interpreter, caller interface{}, arguments []interface{}) interface{} {
+	if len(arguments) > 1 {
+		panic(errors.NewRuntimeError(errors.WrongNumberOfArguments, "method 'collate' expects at most 1 argument"))
+	}
+
+	locale := language.Und
+	if len(arguments) == 1 {
+		name, ok := arguments[0].(string)
+		if !ok {
+			panic(errors.NewRuntimeError(errors.OperandsMustBeString, "the locale passed to method 'collate' must be a string"))
+		}
+		tag, err := language.Parse(name)
+		if err != nil {
+			panic(errors.NewRuntimeError(errors.UnknownLocale, fmt.Sprintf("unknown locale '%s'", name)))
+		}
+		locale = tag
+	}
+
+	list, ok := caller.(*OrderedMap)
+	if !ok || list.Len() != 2 {
+		panic(errors.NewRuntimeError(errors.WrongNumberOfArguments, "method 'collate' must be called on a list of 2 strings"))
+	}
+	left, _ := list.Get(list.Keys()[0])
+	right, _ := list.Get(list.Keys()[1])
+	if !isString(left, right) {
+		panic(errors.NewRuntimeError(errors.OperandsMustBeString, "method 'collate' can only compare strings"))
+	}
+
+	return collate(left.(string), right.(string), locale)
+}
+
+func (c Collate) String() string {
+	return "<native fn>"
+}
+
+func makeIntRange(min, max int) *OrderedMap {
+	a := NewOrderedMap()
+	for i := min; i <= max; i++ {
+		a.Set(i, i)
+	}
+	return a
+}
+
 func makeRange(min, max float64) *OrderedMap {
 	a := NewOrderedMap()
 	for i := min; i <= max; i++ {
@@ -110,14 +200,15 @@ func (r Range) String() string {
 	return "<native fn>"
 }
 
-func printValue(value interface{}) string {
+// PrintValue formats a value the way the print function writes it
+func PrintValue(value interface{}) string {
 	var output string
 	switch t := value.(type) {
 	case *OrderedMap:
 		output = "("
 		for index, key := range t.Keys() {
 			value, _ := t.Get(key)
-			output += printValue(value)
+			output += PrintValue(value)
 			if index < t.Len()-1 {
 				output += ", "
 			}
@@ -2,15 +2,22 @@ package interpreter
 
 import (
 	"fmt"
-	"io"
-	"os"
+	"strings"
+	"tim/diagnostics"
 	"tim/env"
 	"tim/errors"
 	"tim/token"
 	"tim/tree"
 )
 
-func Interpret(statements []tree.Stmt, printPanics bool) (result []interface{}) {
+// Interpret executes the statements with a new interpreter and returns the value of each one.
+// If a statement fails, the values of the statements before it are returned with the error.
+func Interpret(statements []tree.Stmt) ([]interface{}, error) {
+	return New().Interpret(statements)
+}
+
+// New creates an interpreter whose environment persists across calls to Execute
+func New() *Interpreter {
 	interpreter := &Interpreter{
 		Level: 0,
 		Environment: &env.Environment{
@@ -23,32 +30,56 @@ func Interpret(statements []tree.Stmt, printPanics bool) (result []interface{})
 		},
 	}
 	interpreter.defineGlobals()
-	if printPanics {
-		defer interpreter.printToStdErr()
-	}
-	for _, statement := range statements {
-		result = append(result, interpreter.Execute(statement))
-	}
-	return
+	return interpreter
 }
 
 type Interpreter struct {
 	Level       int
 	Environment *env.Environment
 	Globals     *env.Environment
-	stdErr      io.Writer
+	// Strict rejects conditions that aren't booleans, rather than deciding them by truthiness
+	Strict bool
+
+	// how many function calls deep we are, so that returns outside of a function can be reported
+	functionDepth int
 }
 
-func (i *Interpreter) printToStdErr() {
-	if err := recover(); err != nil {
-		// hide stacktrace
-		// debug.SetTraceback("none")
-		if e, ok := err.(errors.RuntimeError); ok {
-			_, _ = i.stdErr.Write([]byte(e.Error() + "\n"))
-			os.Exit(70)
-		} else {
-			fmt.Printf("Error: %s\n", err)
+// Interpret executes the statements in the interpreter's environment, so that they can use
+// anything defined by earlier calls
+func (i *Interpreter) Interpret(statements []tree.Stmt) (result []interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = toRuntimeError(r)
 		}
+	}()
+	for _, statement := range statements {
+		result = append(result, i.Execute(statement))
+	}
+	return result, nil
+}
+
+// locate is deferred by anything that can fail, so that runtime errors raised without a span,
+// e.g. by the operator helpers, native functions or the environment, get the span of the
+// innermost expression being evaluated when they were raised
+func locate(span token.Span) {
+	if r := recover(); r != nil {
+		if err, ok := r.(*errors.RuntimeError); ok && !err.Span.IsValid() {
+			err.Span = span
+		}
+		panic(r)
+	}
+}
+
+// runtime errors are panicked from deep inside the tree, anything else that's panicked
+// is a bug in the interpreter but is still turned into an error rather than crashing
+func toRuntimeError(r interface{}) *errors.RuntimeError {
+	switch err := r.(type) {
+	case *errors.RuntimeError:
+		return err
+	case error:
+		return errors.NewRuntimeError(errors.InternalError, err.Error())
+	default:
+		return errors.NewRuntimeError(errors.InternalError, fmt.Sprint(err))
 	}
 }
 
@@ -57,9 +88,23 @@ func (i *Interpreter) defineGlobals() {
 	// i.Globals.Define("join", Join{})
 	i.Globals.Define("range", Range{})
 	i.Globals.Define("get", Get{})
+	i.Globals.Define("call", Call{})
+	i.Globals.Define("collate", Collate{})
+}
+
+func (i *Interpreter) VisitAssignExpr(expr tree.Assign) interface{} {
+	defer locate(expr.Span)
+	value := i.Evaluate(expr.Value)
+	if expr.Scope.Local {
+		i.Environment.AssignAt(expr.Scope.Depth, expr.Name, value)
+	} else if err := i.Environment.Assign(expr.Name, value); err != nil {
+		panic(err)
+	}
+	return value
 }
 
 func (i *Interpreter) VisitBinaryExpr(expr tree.Binary) interface{} {
+	defer locate(expr.Span)
 	left := i.Evaluate(expr.Left)
 	right := i.Evaluate(expr.Right)
 
@@ -73,6 +118,22 @@ func (i *Interpreter) VisitBinaryExpr(expr tree.Binary) interface{} {
 		returnValue = divide(left, right)
 	case token.STAR:
 		returnValue = multiply(left, right)
+	case token.PERCENT:
+		returnValue = modulo(left, right)
+	case token.TILDE_SLASH:
+		returnValue = floorDivide(left, right)
+	case token.DOUBLE_STAR:
+		returnValue = power(left, right)
+	case token.AMPERSAND:
+		returnValue = bitwiseAnd(left, right)
+	case token.PIPE:
+		returnValue = bitwiseOr(left, right)
+	case token.CARET:
+		returnValue = bitwiseXor(left, right)
+	case token.SHIFT_LEFT:
+		returnValue = shiftLeft(left, right)
+	case token.SHIFT_RIGHT:
+		returnValue = shiftRight(left, right)
 	case token.GREATER:
 		returnValue = greaterThan(left, right)
 	case token.GREATER_EQUAL:
@@ -93,25 +154,72 @@ func (i *Interpreter) VisitLiteralExpr(expr tree.Literal) interface{} {
 	return expr.Value
 }
 
+// the right side is only evaluated when the left doesn't decide the result,
+// and whichever side decides it is the value of the expression
+func (i *Interpreter) VisitLogicalExpr(expr tree.Logical) interface{} {
+	left := i.Evaluate(expr.Left)
+	if expr.Operator.Type == token.OR {
+		if i.isTrue(left, tree.SpanOf(expr.Left)) {
+			return left
+		}
+	} else if !i.isTrue(left, tree.SpanOf(expr.Left)) {
+		return left
+	}
+	right := i.Evaluate(expr.Right)
+	if i.Strict {
+		// so that the result is always a boolean too
+		i.isTrue(right, tree.SpanOf(expr.Right))
+	}
+	return right
+}
+
 func (i *Interpreter) VisitGroupingExpr(expr tree.Grouping) interface{} {
 	return i.Evaluate(expr.Expression)
 }
 
+// strings are interpolated as they are, anything else is formatted the way print would
+func (i *Interpreter) VisitInterpolationExpr(expr tree.Interpolation) interface{} {
+	var output strings.Builder
+	for _, part := range expr.Parts {
+		value := i.Evaluate(part)
+		if str, ok := value.(string); ok {
+			output.WriteString(str)
+		} else {
+			output.WriteString(PrintValue(value))
+		}
+	}
+	return output.String()
+}
+
 func (i *Interpreter) VisitUnaryExpr(expr tree.Unary) interface{} {
-	right := i.Evaluate(expr)
+	defer locate(expr.Span)
+	right := i.Evaluate(expr.Right)
 	switch expr.Operator.Type {
 	case token.BANG:
-		return !i.IsTruthy(right)
+		return !i.isTrue(right, tree.SpanOf(expr.Right))
 	case token.MINUS:
-		return right.(float64) * -1 // ? zeros are going to be a PITA
+		return negate(right)
+	case token.PLUS:
+		return unaryPlus(right)
+	case token.TILDE:
+		return bitwiseNot(right)
 	}
 	return nil
 }
 
 func (i *Interpreter) VisitVariableExpr(expr tree.Variable) interface{} {
+	defer locate(expr.Span)
+	if expr.Scope.Local {
+		val, err := i.Environment.GetAt(expr.Scope.Depth, expr.Name)
+		if err != nil {
+			panic(err)
+		}
+		return val
+	}
 	return i.lookupVariable(expr.Name)
 }
 
+// globals, and variables the resolver hasn't seen, are looked up by name
 func (i *Interpreter) lookupVariable(name token.Token) interface{} {
 	global, err := i.Globals.Get(name)
 	if err != nil {
@@ -125,6 +233,12 @@ func (i *Interpreter) lookupVariable(name token.Token) interface{} {
 
 	val, err := i.Environment.Get(name)
 	if err != nil {
+		if runtimeErr, ok := err.(*errors.RuntimeError); ok {
+			candidates := append(i.Globals.Names(), i.Environment.Names()...)
+			if suggestion, ok := diagnostics.Suggest(name.Text, candidates); ok {
+				runtimeErr.Hint = fmt.Sprintf("did you mean `%s`?", suggestion)
+			}
+		}
 		panic(err)
 	}
 	return val
@@ -141,22 +255,31 @@ func (i *Interpreter) lookupVariable(name token.Token) interface{} {
 // }
 
 func (i *Interpreter) callFunction(stmt tree.CallStmt, caller interface{}) interface{} {
+	defer locate(stmt.Span)
 	callee := i.Evaluate(stmt.Callee)
 	var arguments []interface{}
 	for _, arg := range stmt.Arguments {
 		arguments = append(arguments, i.Evaluate(arg))
 	}
-	return callee.(Callable).Call(i, caller, arguments)
+	callable, ok := callee.(Callable)
+	if !ok {
+		panic(errors.NewRuntimeError(errors.NotCallable, "can only call functions"))
+	}
+	return callable.Call(i, caller, arguments)
 }
 
+// a return unwinds the stack with a panic, which the function being called recovers
 func (i *Interpreter) VisitReturnStmt(stmt tree.ReturnStmt) interface{} {
+	if i.functionDepth == 0 {
+		panic(errors.NewRuntimeErrorAt(stmt.Span, errors.ReturnOutsideFunction, "can't return from top-level code"))
+	}
 	var value interface{}
 	if stmt.Value != nil {
 		value = i.Execute(stmt.Value)
 	}
-	return Return{
+	panic(Return{
 		Value: value,
-	}
+	})
 }
 
 func (i *Interpreter) VisitExpressionStmt(stmt tree.ExpressionStmt) interface{} {
@@ -179,6 +302,42 @@ func (i *Interpreter) VisitVariableStmt(stmt tree.VariableStmt) interface{} {
 }
 
 func (i *Interpreter) VisitFunctionStmt(stmt tree.FuncStmt) interface{} {
+	return Function{
+		Declaration: stmt,
+		Closure:     i.Environment,
+	}
+}
+
+// lists at the top of a function body declare their variables in the function's environment,
+// the same way top level lists use the global one
+func (i *Interpreter) executeFunctionBody(body []tree.Stmt, environment *env.Environment) (result interface{}) {
+	previous, previousLevel := i.Environment, i.Level
+	i.Environment, i.Level = environment, 0
+	i.functionDepth++
+	defer func() {
+		i.Environment, i.Level = previous, previousLevel
+		i.functionDepth--
+		if r := recover(); r != nil {
+			returnValue, ok := r.(Return)
+			if !ok {
+				panic(r)
+			}
+			result = returnValue.Value
+		}
+	}()
+	for _, stmt := range body {
+		i.Execute(stmt)
+	}
+	return nil
+}
+
+// runs the action of the first branch whose condition is truthy
+func (i *Interpreter) VisitConditionalStmt(stmt tree.ConditionalStmt) interface{} {
+	for _, branch := range stmt.Branches {
+		if branch.Condition == nil || i.isTrue(i.Evaluate(branch.Condition), tree.SpanOf(branch.Condition)) {
+			return i.Execute(branch.Action)
+		}
+	}
 	return nil
 }
 
@@ -198,6 +357,9 @@ func (i *Interpreter) VisitListStmt(stmt tree.ListStmt) interface{} {
 func (i *Interpreter) executeList(items []tree.Stmt, functions []tree.CallStmt, environment *env.Environment) interface{} {
 	previous := i.Environment
 	i.Environment = environment
+	defer func() {
+		i.Environment = previous
+	}()
 	values := NewOrderedMap()
 	for index, item := range items {
 		value := i.Execute(item)
@@ -223,28 +385,44 @@ func (i *Interpreter) executeList(items []tree.Stmt, functions []tree.CallStmt,
 			}
 		}
 	}
-	i.Environment = previous
 	return returnVal
 }
 
+// IsTruthy is the rule conditionals and the logical operators share: nil, false, zero,
+// the empty string and the empty list are falsy, and everything else is truthy
 func (i *Interpreter) IsTruthy(val interface{}) bool {
-	if val == nil {
+	switch value := val.(type) {
+	case nil:
 		return false
-	}
-	switch i := val.(type) {
+	case bool:
+		return value
+	case int:
+		return value != 0
 	case float64:
+		return value != 0
 	case float32:
-	case int64:
-	case int32:
-	case int:
-	case uint64:
-	case uint32:
-	case uint:
-		return i != 0
+		return value != 0
+	case string:
+		return value != ""
+	case *OrderedMap:
+		return value.Len() > 0
 	}
 	return true
 }
 
+// isTrue decides a condition. In strict mode the condition has to be a boolean,
+// otherwise it's whether the value is truthy.
+func (i *Interpreter) isTrue(value interface{}, span token.Span) bool {
+	if !i.Strict {
+		return i.IsTruthy(value)
+	}
+	condition, ok := value.(bool)
+	if !ok {
+		panic(errors.NewRuntimeErrorAt(span, errors.ConditionMustBeBoolean, fmt.Sprintf("condition must be a boolean, got %s", PrintValue(value))))
+	}
+	return condition
+}
+
 func (i *Interpreter) Evaluate(expr tree.Expr) interface{} {
 	return expr.Accept(i)
 }
@@ -2,15 +2,18 @@ package interpreter_test
 
 import (
 	"bytes"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"tim/errors"
 	"tim/interpreter"
 	"tim/lexer"
 	"tim/parser"
+	"tim/resolver"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -18,7 +21,7 @@ import (
 type InterpretedCase struct {
 	InputString string
 	Expected    interface{}
-	Err         error
+	Err         errors.Code
 	StdOut      string
 }
 
@@ -42,7 +45,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"subtraction: string and number": {
 			InputString: "(\"hello\" - 13).print()",
-			Err:         errors.NewRuntimeError(errors.OperandsMustBeNumber),
+			Err:         errors.OperandsMustBeNumber,
 		},
 		"subtraction: integer and float": {
 			InputString: "(5 - 2.5).print()",
@@ -58,7 +61,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"multiplication: 1 integer and 1 string": {
 			InputString: "(2 * \"foo\").print()",
-			Err:         errors.NewRuntimeError(errors.OperandsMustBeNumber),
+			Err:         errors.OperandsMustBeNumber,
 		},
 		"division: 2 integers": {
 			InputString: "(300 / 2).print()",
@@ -70,11 +73,11 @@ func TestExpressions(t *testing.T) {
 		},
 		"division: 1 integer and 1 string": {
 			InputString: "(2.5 / \"foo\").print()",
-			Err:         errors.NewRuntimeError(errors.OperandsMustBeNumber),
+			Err:         errors.OperandsMustBeNumber,
 		},
 		"division by zero panics": {
 			InputString: "(10 / 0).print()",
-			Err:         errors.NewRuntimeError(errors.DivisionByZero),
+			Err:         errors.DivisionByZero,
 		},
 		"greater than: 2 integers": {
 			InputString: "(3 > 2).print()",
@@ -82,7 +85,15 @@ func TestExpressions(t *testing.T) {
 		},
 		"greater than: 1 integer and 1 string": {
 			InputString: "(3 > \"hello\").print()",
-			Err:         errors.NewRuntimeError(errors.OperandsMustBeNumber),
+			Err:         errors.OperandsMustBeNumber,
+		},
+		"greater than: 2 strings": {
+			InputString: "(\"b\" > \"a\", \"a\" > \"ab\").print()",
+			StdOut:      "(true, false)",
+		},
+		"greater than: strings compare by code point": {
+			InputString: "(\"a\" > \"Z\", \"é\" > \"z\").print()",
+			StdOut:      "(true, true)",
 		},
 		"greater than: 1 integer and 1 float": {
 			InputString: "(3 > 2.5).print()",
@@ -93,8 +104,8 @@ func TestExpressions(t *testing.T) {
 			StdOut:      "(true)",
 		},
 		"greater than or equal to: 2 strings": {
-			InputString: "(\"foo\" >= \"bar\").print()",
-			Err:         errors.NewRuntimeError(errors.OperandsMustBeNumber),
+			InputString: "(\"foo\" >= \"bar\", \"bar\" >= \"bar\").print()",
+			StdOut:      "(true, true)",
 		},
 		"greater than or equal to: 1 integer and 1 float": {
 			InputString: "(3 >= 3.0).print()",
@@ -105,8 +116,8 @@ func TestExpressions(t *testing.T) {
 			StdOut:      "(true)",
 		},
 		"less than: 2 strings": {
-			InputString: "(\"foo\" < \"bar\").print()",
-			Err:         errors.NewRuntimeError(errors.OperandsMustBeNumber),
+			InputString: "(\"foo\" < \"bar\", \"bar\" < \"foo\").print()",
+			StdOut:      "(false, true)",
 		},
 		"less than: 1 integer and 1 float": {
 			InputString: "(3 < 3.5).print()",
@@ -117,8 +128,8 @@ func TestExpressions(t *testing.T) {
 			StdOut:      "(true)",
 		},
 		"less than or equal to: 2 strings": {
-			InputString: "(\"foo\" <= \"bar\").print()",
-			Err:         errors.NewRuntimeError(errors.OperandsMustBeNumber),
+			InputString: "(\"foo\" <= \"bar\", \"foo\" <= \"foo\").print()",
+			StdOut:      "(false, true)",
 		},
 		"less than or equal to: 1 integer and 1 float": {
 			InputString: "(3 <= 3.0).print()",
@@ -152,6 +163,14 @@ func TestExpressions(t *testing.T) {
 			InputString: "(\"hello \" + \"world\").print()",
 			StdOut:      "(\"hello world\")",
 		},
+		"interpolation": {
+			InputString: "(name: \"tim\", age: 27)\n(\"hello ${name}, you are ${age + 1}\").print()",
+			StdOut:      "(\"hello tim, you are 28\")",
+		},
+		"interpolation: nested string": {
+			InputString: "(\"a ${'b ${1 + 1} c'} d\").print()",
+			StdOut:      "(\"a b 2 c d\")",
+		},
 		"concatenation: 1 string and 1 number": {
 			InputString: "(\"hello \" + 123).print()",
 			StdOut:      "(\"hello 123\")",
@@ -162,19 +181,24 @@ func TestExpressions(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			l := lexer.New(testcase.InputString)
 			p := parser.New(l.Tokens)
-			parsed := p.Parse()
+			parsed, errs := resolver.Resolve(p.Parse())
+			assert.Empty(t, errs)
 
-			if testcase.Err != nil {
-				assert.PanicsWithError(t, testcase.Err.Error(), func() {
-					interpreter.Interpret(parsed, false)
-				}, "did not panic with '%s'", testcase.Err.Error())
+			if testcase.Err != "" {
+				_, err := interpreter.Interpret(parsed)
+				var runtimeErr *errors.RuntimeError
+				if assert.True(t, stderrors.As(err, &runtimeErr), "expected a runtime error, got: %v", err) {
+					assert.Equal(t, testcase.Err, runtimeErr.Code)
+				}
 			} else if testcase.StdOut != "" {
 				stdOut := captureStdOut(func() {
-					interpreter.Interpret(parsed, true)
+					_, err := interpreter.Interpret(parsed)
+					assert.NoError(t, err)
 				})
 				assert.Equal(t, testcase.StdOut, stdOut, "expressions do not match", testcase.StdOut, stdOut)
 			} else {
-				actual := interpreter.Interpret(parsed, true)
+				actual, err := interpreter.Interpret(parsed)
+				assert.NoError(t, err)
 				assert.Equal(t, testcase.Expected, actual, "expressions do not match", fmt.Sprintf("%t", testcase.Expected), fmt.Sprintf("%t", actual))
 			}
 		})
@@ -203,31 +227,494 @@ func TestStatements(t *testing.T) {
 			InputString: "(one: 1, two: 2, three: 3).get(\"one\").print()",
 			StdOut:      "1",
 		},
+		"call user defined function": {
+			InputString: "(add: (x, y) => { >> x + y })\n(1, 2).call(add).print()",
+			StdOut:      "3",
+		},
+		"call user defined function with no arguments": {
+			InputString: "(hello: () => { >> \"hello\" })\n().call(hello).print()",
+			StdOut:      "\"hello\"",
+		},
+		"closure captures declaring environment": {
+			InputString: `
+				(makeGreeter: (name) => {
+					(greet: () => { >> "hello " + name })
+					>> greet
+				})
+				(greeter: ("tim").call(makeGreeter))
+				(name: "paul")
+				().call(greeter).print()
+			`,
+			StdOut: "\"hello tim\"",
+		},
+		"return from nested list": {
+			InputString: `
+				(first: (x) => {
+					(x, (>> x))
+					>> 0
+				})
+				(7).call(first).print()
+			`,
+			StdOut: "7",
+		},
+		"closure updates captured variable": {
+			InputString: `
+				(makeCounter: () => {
+					(count: 0)
+					(increment: () => {
+						count = count + 1
+						>> count
+					})
+					>> increment
+				})
+				(counter: ().call(makeCounter))
+				().call(counter)
+				().call(counter).print()
+			`,
+			StdOut: "2",
+		},
+		"variable lookup from deeply nested list": {
+			InputString: "(x: 1)\n((((x).print())))",
+			StdOut:      "(1)",
+		},
+		"nested declaration shadows outer variable": {
+			InputString: "(x: 1)\n((x: 2))\n(x).print()",
+			StdOut:      "(1)",
+		},
+		"assignment updates outer variable": {
+			InputString: "(x: 1)\n((x = 2))\n(x).print()",
+			StdOut:      "(2)",
+		},
+		"assignment to undefined variable": {
+			InputString: "(y = 2)",
+			Err:         errors.UndefinedVariable,
+		},
+		"conditional runs first truthy branch": {
+			InputString: "?(\n(false) => (\"no\").print(),\n(true) => (\"yes\").print(),\n() => (\"else\").print()\n)",
+			StdOut:      "(\"yes\")",
+		},
+		"conditional runs else branch": {
+			InputString: "?((false) => (\"no\").print(), () => (\"else\").print())",
+			StdOut:      "(\"else\")",
+		},
+		"conditional with named branch returns from function": {
+			InputString: `
+				(classify: (n) => {
+					?(
+						(n < 0) => >> "negative",
+						zero: (n == 0) => >> "zero",
+						() => >> "positive"
+					)
+				})
+				(0).call(classify).print()
+			`,
+			StdOut: "\"zero\"",
+		},
+		"modulo: 2 integers": {
+			InputString: "(7 % 3).print()",
+			StdOut:      "(1)",
+		},
+		"modulo: takes the sign of the divisor": {
+			InputString: "(a: 0 - 7, b: 0 - 3)\n(a % 3, 7 % b).print()",
+			StdOut:      "(2, -2)",
+		},
+		"modulo: 1 integer, 1 float": {
+			InputString: "(7.5 % 2).print()",
+			StdOut:      "(1.5)",
+		},
+		"modulo: by zero": {
+			InputString: "(5 % 0).print()",
+			Err:         errors.DivisionByZero,
+		},
+		"modulo: string and number": {
+			InputString: "(\"a\" % 2).print()",
+			Err:         errors.OperandsMustBeNumber,
+		},
+		"floor division: 2 integers": {
+			InputString: "(7 ~/ 2).print()",
+			StdOut:      "(3)",
+		},
+		"floor division: rounds down": {
+			InputString: "(a: 0 - 7)\n(a ~/ 2).print()",
+			StdOut:      "(-4)",
+		},
+		"floor division: 1 integer, 1 float": {
+			InputString: "(7.5 ~/ 2).print()",
+			StdOut:      "(3)",
+		},
+		"floor division: by zero": {
+			InputString: "(1 ~/ 0).print()",
+			Err:         errors.DivisionByZero,
+		},
+		"exponent: 2 integers": {
+			InputString: "(2 ** 10).print()",
+			StdOut:      "(1024)",
+		},
+		"exponent: right-associative": {
+			InputString: "(2 ** 3 ** 2).print()",
+			StdOut:      "(512)",
+		},
+		"exponent: negative exponent": {
+			InputString: "(a: 0 - 3)\n(2 ** a).print()",
+			StdOut:      "(0.125)",
+		},
+		"exponent: float": {
+			InputString: "(4.0 ** 0.5).print()",
+			StdOut:      "(2)",
+		},
+		"exponent: overflow becomes a float": {
+			InputString: "(2 ** 64, 3 ** 50).print()",
+			StdOut:      "(1.8446744073709552e+19, 7.178979876918526e+23)",
+		},
+		"exponent: largest int": {
+			InputString: "(a: 0 - 2)\n(2 ** 62, a ** 63, -2 ** 63).print()",
+			StdOut:      "(4611686018427387904, -9223372036854775808, -9.223372036854776e+18)",
+		},
+		"exponent: binds tighter than multiply": {
+			InputString: "(2 * 3 ** 2).print()",
+			StdOut:      "(18)",
+		},
+		"unary minus: integer": {
+			InputString: "(a: 3)\n(-a).print()",
+			StdOut:      "(-3)",
+		},
+		"unary minus: float": {
+			InputString: "(-2.5).print()",
+			StdOut:      "(-2.5)",
+		},
+		"unary minus: twice": {
+			InputString: "(- -3, 2 - -3).print()",
+			StdOut:      "(3, 5)",
+		},
+		"unary minus: binds looser than exponent": {
+			InputString: "(-2 ** 2).print()",
+			StdOut:      "(-4)",
+		},
+		"unary minus: string": {
+			InputString: "(-\"a\").print()",
+			Err:         errors.OperandMustBeNumber,
+		},
+		"unary plus": {
+			InputString: "(+3, +2.5).print()",
+			StdOut:      "(3, 2.5)",
+		},
+		"unary plus: boolean": {
+			InputString: "(+true).print()",
+			Err:         errors.OperandMustBeNumber,
+		},
+		"unary not: numbers": {
+			InputString: "(!3, !2.5).print()",
+			StdOut:      "(false, false)",
+		},
+		"bitwise and, or and xor": {
+			InputString: "(6 & 3, 6 | 3, 6 ^ 3).print()",
+			StdOut:      "(2, 7, 5)",
+		},
+		"bitwise not": {
+			InputString: "(~5).print()",
+			StdOut:      "(-6)",
+		},
+		"shifts": {
+			InputString: "(1 << 4, 256 >>> 4).print()",
+			StdOut:      "(16, 16)",
+		},
+		"right shift fills with zeros": {
+			InputString: "(a: 0 - 8)\n(a >>> 60).print()",
+			StdOut:      "(15)",
+		},
+		"bitwise operators bind tighter than comparison": {
+			InputString: "(5 & 1 == 1, 1 | 2 ^ 3 & 1).print()",
+			StdOut:      "(true, 3)",
+		},
+		"bitwise and: float": {
+			InputString: "(6.0 & 3).print()",
+			Err:         errors.OperandsMustBeInteger,
+		},
+		"shift: float": {
+			InputString: "(1 << 2.0).print()",
+			Err:         errors.OperandsMustBeInteger,
+		},
+		"bitwise not: float": {
+			InputString: "(~1.5).print()",
+			Err:         errors.OperandMustBeInteger,
+		},
+		"shift: negative count": {
+			InputString: "(a: 0 - 1)\n(1 << a).print()",
+			Err:         errors.NegativeShiftCount,
+		},
+		"falsy values": {
+			InputString: "(empty: ())\n(!nil, !false, !0, !0.0, !\"\", !empty).print()",
+			StdOut:      "(true, true, true, true, true, true)",
+		},
+		"truthy values": {
+			InputString: "(list: (0))\n(!true, !1, !0.5, !\"0\", !list).print()",
+			StdOut:      "(false, false, false, false, false)",
+		},
+		"conditional skips a zero condition": {
+			InputString: "(count: 0)\n?((count) => (\"some\").print(), () => (\"none\").print())",
+			StdOut:      "(\"none\")",
+		},
+		"conditional skips an empty string": {
+			InputString: "(name: \"\")\n?((name) => (name).print(), () => (\"anonymous\").print())",
+			StdOut:      "(\"anonymous\")",
+		},
+		"logical operators use truthiness": {
+			InputString: "(0 || \"zero\", \"\" && 1).print()",
+			StdOut:      "(\"zero\", \"\")",
+		},
+		"range: integers": {
+			InputString: "().range(1, 3).print()",
+			StdOut:      "(1, 2, 3)",
+		},
+		"range: floats": {
+			InputString: "().range(0.5, 2).print()",
+			StdOut:      "(0.5, 1.5)",
+		},
+		"range: not numbers": {
+			InputString: "().range(\"a\", 3)",
+			Err:         errors.OperandsMustBeNumber,
+		},
+		"range: one argument": {
+			InputString: "().range(3)",
+			Err:         errors.WrongNumberOfArguments,
+		},
+		"collate: natural order": {
+			InputString: "((\"file10\", \"file2\").collate(), (\"file2\", \"file10\").collate(), (\"file2\", \"file2\").collate()).print()",
+			StdOut:      "(1, -1, 0)",
+		},
+		"collate: ignores case": {
+			InputString: "((\"Banana\", \"apple\").collate(), (\"apple\", \"Banana\").collate()).print()",
+			StdOut:      "(1, -1)",
+		},
+		"collate: accents": {
+			InputString: "((\"éclair\", \"zebra\").collate(), (\"Ångström\", \"Bach\").collate()).print()",
+			StdOut:      "(-1, -1)",
+		},
+		"collate: locale": {
+			InputString: "((\"Ångström\", \"zebra\").collate(), (\"Ångström\", \"zebra\").collate(\"sv\")).print()",
+			StdOut:      "(-1, 1)",
+		},
+		"collate: unknown locale": {
+			InputString: "(\"a\", \"b\").collate(\"en_GB!\")",
+			Err:         errors.UnknownLocale,
+		},
+		"collate: locale not a string": {
+			InputString: "(\"a\", \"b\").collate(1)",
+			Err:         errors.OperandsMustBeString,
+		},
+		"collate: not strings": {
+			InputString: "(1, \"a\").collate()",
+			Err:         errors.OperandsMustBeString,
+		},
+		"collate: wrong number of strings": {
+			InputString: "(\"a\").collate()",
+			Err:         errors.WrongNumberOfArguments,
+		},
+		"logical and": {
+			InputString: "(true && false, true && true).print()",
+			StdOut:      "(false, true)",
+		},
+		"logical or": {
+			InputString: "(false || true, false || false).print()",
+			StdOut:      "(true, false)",
+		},
+		"logical not": {
+			InputString: "(!true, !false, !nil).print()",
+			StdOut:      "(false, true, true)",
+		},
+		"and binds tighter than or": {
+			InputString: "(true || false && false).print()",
+			StdOut:      "(true)",
+		},
+		"and short-circuits": {
+			InputString: "(x: nil, y: x != nil && x / 0 > 1)\n(y).print()",
+			StdOut:      "(false)",
+		},
+		"or short-circuits": {
+			InputString: "(true || 1 / 0).print()",
+			StdOut:      "(true)",
+		},
+		"logical operators return the deciding operand": {
+			InputString: "(nil || \"default\", \"first\" && \"second\").print()",
+			StdOut:      "(\"default\", \"second\")",
+		},
+		"right side is evaluated when the left doesn't decide": {
+			InputString: "(false || 1 / 0).print()",
+			Err:         errors.DivisionByZero,
+		},
+		"call user defined function with too few arguments": {
+			InputString: "(add: (x, y) => { >> x + y })\n(1).call(add).print()",
+			Err:         errors.WrongNumberOfArguments,
+		},
 	}
 
 	for name, testcase := range cases {
 		t.Run(name, func(t *testing.T) {
 			l := lexer.New(testcase.InputString)
 			p := parser.New(l.Tokens)
-			parsed := p.Parse()
+			parsed, errs := resolver.Resolve(p.Parse())
+			assert.Empty(t, errs)
 
-			if testcase.Err != nil {
-				assert.PanicsWithError(t, testcase.Err.Error(), func() {
-					interpreter.Interpret(parsed, false)
-				}, "did not panic with '%s'", testcase.Err.Error())
+			if testcase.Err != "" {
+				_, err := interpreter.Interpret(parsed)
+				var runtimeErr *errors.RuntimeError
+				if assert.True(t, stderrors.As(err, &runtimeErr), "expected a runtime error, got: %v", err) {
+					assert.Equal(t, testcase.Err, runtimeErr.Code)
+				}
 			} else if testcase.StdOut != "" {
 				stdOut := captureStdOut(func() {
-					interpreter.Interpret(parsed, true)
+					_, err := interpreter.Interpret(parsed)
+					assert.NoError(t, err)
 				})
 				assert.Equal(t, testcase.StdOut, stdOut, "expressions do not match", testcase.StdOut, stdOut)
 			} else {
-				actual := interpreter.Interpret(parsed, true)
+				actual, err := interpreter.Interpret(parsed)
+				assert.NoError(t, err)
 				assert.Equal(t, testcase.Expected, actual, "expressions do not match", fmt.Sprintf("%t", testcase.Expected), fmt.Sprintf("%t", actual))
 			}
 		})
 	}
 }
 
+func TestRuntimeErrorType(t *testing.T) {
+	tokens, err := lexer.Lex("(a: 1, b: a / 0)")
+	assert.NoError(t, err)
+	statements, err := parser.Parse(tokens)
+	assert.NoError(t, err)
+
+	_, err = interpreter.Interpret(statements)
+	var runtimeErr *errors.RuntimeError
+	assert.True(t, stderrors.As(err, &runtimeErr))
+	assert.Equal(t, errors.DivisionByZero, runtimeErr.Code)
+	assert.Equal(t, "T2003", runtimeErr.Diagnostic().Code)
+	assert.Equal(t, "[line 1] Error: division by zero", runtimeErr.Error())
+}
+
+func TestRuntimeErrorSpans(t *testing.T) {
+	cases := map[string]struct {
+		InputString string
+		// start line and column, then end line and column
+		Span [4]int
+	}{
+		"binary operands":    {InputString: "(a: 1,\n  b: a - \"x\")", Span: [4]int{2, 6, 2, 13}},
+		"division by zero":   {InputString: "(1 + 2 / 0)", Span: [4]int{1, 6, 1, 11}},
+		"undefined variable": {InputString: "(1,\n  fib)", Span: [4]int{2, 3, 2, 6}},
+		"unary operand":      {InputString: "(1, -\"x\")", Span: [4]int{1, 5, 1, 9}},
+		"wrong arity":        {InputString: "(add: (x, y) => { >> x + y })\n(1).call(add)", Span: [4]int{2, 5, 2, 14}},
+		"inside a function":  {InputString: "(f: (x) => { >> x / 0 })\n(1).call(f)", Span: [4]int{1, 17, 1, 22}},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			tokens, err := lexer.Lex(testcase.InputString)
+			assert.NoError(t, err)
+			p := parser.New(tokens)
+			p.File = "test.tim"
+			statements, errs := resolver.Resolve(p.Parse())
+			assert.Empty(t, p.Errors)
+			assert.Empty(t, errs)
+
+			_, err = interpreter.Interpret(statements)
+			var runtimeErr *errors.RuntimeError
+			if assert.True(t, stderrors.As(err, &runtimeErr), "expected a runtime error, got: %v", err) {
+				span := runtimeErr.Span
+				assert.Equal(t, "test.tim", span.File)
+				assert.Equal(t, testcase.Span, [4]int{span.Start.Line, span.Start.Column, span.End.Line, span.End.Column})
+			}
+		})
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	cases := map[string]struct {
+		InputString string
+		Err         errors.Code
+		// the value that isn't a boolean: start line and column, then end line and column
+		Span [4]int
+	}{
+		"boolean condition":          {InputString: "?((1 > 0) => 1)"},
+		"boolean logical operands":   {InputString: "(true && !false || false)"},
+		"number condition":           {InputString: "?((1) => 1)", Err: errors.ConditionMustBeBoolean, Span: [4]int{1, 4, 1, 5}},
+		"string negated":             {InputString: "(!\"a\")", Err: errors.ConditionMustBeBoolean, Span: [4]int{1, 3, 1, 6}},
+		"nil on the left of or":      {InputString: "(nil || true)", Err: errors.ConditionMustBeBoolean, Span: [4]int{1, 2, 1, 5}},
+		"number on the right of and": {InputString: "(true && 1)", Err: errors.ConditionMustBeBoolean, Span: [4]int{1, 10, 1, 11}},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			statements, err := parser.Parse(lexer.New(testcase.InputString).Tokens)
+			assert.NoError(t, err)
+
+			i := interpreter.New()
+			i.Strict = true
+			_, err = i.Interpret(statements)
+			if testcase.Err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var runtimeErr *errors.RuntimeError
+			if assert.True(t, stderrors.As(err, &runtimeErr), "expected a runtime error, got: %v", err) {
+				assert.Equal(t, testcase.Err, runtimeErr.Code)
+				span := runtimeErr.Span
+				assert.Equal(t, testcase.Span, [4]int{span.Start.Line, span.Start.Column, span.End.Line, span.End.Column})
+			}
+		})
+	}
+}
+
+func TestInterpreterKeepsState(t *testing.T) {
+	i := interpreter.New()
+
+	statements, _ := parser.Parse(lexer.New("a: 1 / 0").Tokens)
+	_, err := i.Interpret(statements)
+	assert.Error(t, err)
+
+	// a failed statement doesn't stop later ones from using earlier definitions
+	statements, _ = parser.Parse(lexer.New("b: 2").Tokens)
+	_, err = i.Interpret(statements)
+	assert.NoError(t, err)
+	statements, _ = parser.Parse(lexer.New("b + 1").Tokens)
+	values, err := i.Interpret(statements)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{3}, values)
+}
+
+func TestCollateFromSeveralInterpreters(t *testing.T) {
+	statements, _ := parser.Parse(lexer.New("(\"Ångström\", \"zebra\").collate(\"sv\")").Tokens)
+
+	// the collators are shared, so interpreters running at the same time mustn't trip over each other
+	var wg sync.WaitGroup
+	results := make([]interface{}, 8)
+	for n := range results {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			values, _ := interpreter.New().Interpret(statements)
+			results[n] = values[0]
+		}(n)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		assert.Equal(t, 1, result)
+	}
+}
+
+func TestUndefinedVariableHint(t *testing.T) {
+	statements, _ := parser.Parse(lexer.New("(fib: 1, total: fbi + 1)").Tokens)
+	_, err := interpreter.Interpret(statements)
+	var runtimeErr *errors.RuntimeError
+	if assert.True(t, stderrors.As(err, &runtimeErr)) {
+		assert.Equal(t, "did you mean `fib`?", runtimeErr.Hint)
+	}
+
+	statements, _ = parser.Parse(lexer.New("(nothing + 1)").Tokens)
+	_, err = interpreter.Interpret(statements)
+	if assert.True(t, stderrors.As(err, &runtimeErr)) {
+		assert.Empty(t, runtimeErr.Hint)
+	}
+}
+
 func captureStdOut(f func()) string {
 	old := os.Stdout // keep backup of the real stdout
 	r, w, _ := os.Pipe()
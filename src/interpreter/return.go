@@ -1,5 +1,6 @@
 package interpreter
 
+// Return is panicked by a return statement and recovered by the enclosing function call
 type Return struct {
 	Value interface{}
 }
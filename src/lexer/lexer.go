@@ -1,167 +1,246 @@
 package lexer
 
 import (
+	stderrors "errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"tim/diagnostics"
+	"tim/errors"
 	"tim/token"
 	"unicode"
-
-	"golang.org/x/exp/slices"
+	"unicode/utf8"
 )
 
+// returned by peek and friends when there are no characters left
+const eof rune = -1
+
+// the tokens slice starts small and is left to append to grow, as sizing it from the
+// input would reserve far more memory than most programs need
+const initialTokens = 64
+
+// Lex scans the input into tokens. If the input has errors, the tokens scanned around them
+// are returned along with an ErrorList holding every error.
+func Lex(input string) ([]token.Token, error) {
+	lexer := New(input)
+	if len(lexer.Errors) > 0 {
+		return lexer.Tokens, ErrorList(lexer.Errors)
+	}
+	return lexer.Tokens, nil
+}
+
+// New scans the input, collecting any errors in the lexer's Errors rather than returning them
 func New(input string) Lexer {
 	lexer := Lexer{
 		Input:   input,
+		Tokens:  make([]token.Token, 0, initialTokens),
 		Line:    1,
+		Column:  1,
 		Start:   0,
 		Current: 0,
 	}
-	err := lexer.ReadInput()
-	if err != nil {
-		panic(err)
-	}
+	lexer.ReadInput()
 	return lexer
 }
 
+// Lexer scans the input a rune at a time. Start and Current are byte offsets into the input,
+// while Line and Column are counted in lines and runes from 1.
 type Lexer struct {
-	Input      string
-	Tokens     []token.Token
-	Start      int
-	Current    int
-	Line       int
+	Input       string
+	Tokens      []token.Token
+	Start       int
+	Current     int
+	Line        int
+	Column      int
+	StartLine   int
+	StartColumn int
+	// every problem found in the input, scanning carries on after each one
+	Errors     []*LexError
 	insertSemi bool
+	// one entry per string interpolation we're inside of, innermost last
+	interpolations []interpolation
 }
 
-func (l *Lexer) ReadInput() error {
+// the quote of the string being interpolated and how many braces deep the expression is,
+// so that we know which '}' ends the interpolation. token is the index of the string's
+// INTERPOLATION token, so that the string can be dropped if it's never closed.
+type interpolation struct {
+	quote  rune
+	braces int
+	token  int
+}
+
+func (l *Lexer) ReadInput() {
 	for !l.isAtEnd() {
-		l.Start = l.Current
-		err := l.ReadChar()
-		if err != nil {
-			return err
-		}
+		l.Start, l.StartLine, l.StartColumn = l.Current, l.Line, l.Column
+		l.ReadChar()
 		if l.insertSemi {
 			l.insertSemi = false
 			l.AddToken(token.SEMICOLON, ";", "\\n")
 		}
 	}
-	l.Start++
+	if len(l.interpolations) > 0 {
+		l.errorAt(l.Current, l.Line, l.Column, errors.UnterminatedInterpolation, "unterminated string interpolation")
+
+		// the whole of the outermost string becomes one ILLEGAL token, so the parser doesn't
+		// report the tokens of the unfinished expression as errors of its own
+		start := l.Tokens[l.interpolations[0].token]
+		l.Tokens = l.Tokens[:l.interpolations[0].token]
+		l.Start, l.StartLine, l.StartColumn = start.Position, start.Line, start.Column
+		l.addIllegal()
+	}
+	l.Start, l.StartLine, l.StartColumn = l.Current, l.Line, l.Column
 	l.AddToken(token.EOF, "", "")
-	return nil
 }
 
-func (l *Lexer) ReadChar() error {
+func (l *Lexer) ReadChar() {
 	char := l.NextChar()
 	canInsertSemi := false
 	switch char {
-	case "(":
-		l.AddToken(token.LEFT_PAREN, char, char)
-	case ")":
-		l.AddToken(token.RIGHT_PAREN, char, char)
-		if !slices.Contains([]string{".", ")", ",", "}", "=>"}, l.peek()) {
+	case '(':
+		l.addSymbol(token.LEFT_PAREN)
+	case ')':
+		l.addSymbol(token.RIGHT_PAREN)
+		switch l.peekSignificant() {
+		case '.', ')', ',', '}', '=', '!':
+		default:
 			canInsertSemi = true
 		}
-	case "{":
-		canInsertSemi = false
-		l.AddToken(token.LEFT_BRACE, char, char)
-	case "}":
-		canInsertSemi = false
-		l.AddToken(token.RIGHT_BRACE, char, char)
-	case ",":
-		canInsertSemi = false
-		l.AddToken(token.COMMA, char, char)
-	// case ";":
-	// 	l.AddToken(token.SEMICOLON, char, char)
-	case ".":
-		canInsertSemi = false
-		l.AddToken(token.DOT, char, char)
-	case "+":
-		canInsertSemi = false
-		if l.matchNext("+") {
-			// canInsertSemi = true
-			l.AddToken(token.INCREMENT, "++", "++")
+	case '{':
+		if len(l.interpolations) > 0 {
+			l.interpolations[len(l.interpolations)-1].braces++
+		}
+		l.addSymbol(token.LEFT_BRACE)
+	case '}':
+		if len(l.interpolations) > 0 {
+			current := &l.interpolations[len(l.interpolations)-1]
+			if current.braces == 0 {
+				// the end of an interpolated expression, so carry on with the rest of the string
+				quote := current.quote
+				l.interpolations = l.interpolations[:len(l.interpolations)-1]
+				l.matchString(quote)
+				break
+			}
+			current.braces--
+		}
+		l.addSymbol(token.RIGHT_BRACE)
+	case ',':
+		l.addSymbol(token.COMMA)
+	case '.':
+		l.addSymbol(token.DOT)
+	case '+':
+		if l.matchNext('+') {
+			l.addSymbol(token.INCREMENT)
 		} else {
-			l.AddToken(token.PLUS, char, char)
+			l.addSymbol(token.PLUS)
 		}
-	case "-":
-		canInsertSemi = false
-		if l.matchNext("-") {
-			l.AddToken(token.DECREMENT, "--", "--")
+	case '-':
+		if l.matchNext('-') {
+			l.addSymbol(token.DECREMENT)
 		} else {
-			l.AddToken(token.MINUS, char, char)
+			l.addSymbol(token.MINUS)
 		}
-	case "*":
-		canInsertSemi = false
-		l.AddToken(token.STAR, char, char)
-	case "/":
-		canInsertSemi = false
-		l.AddToken(token.SLASH, char, char)
-	case "?":
-		canInsertSemi = false
-		l.AddToken(token.QUESTION, char, char)
-	case "!":
-		canInsertSemi = false
-		if l.matchNext("=") {
-			l.AddToken(token.BANG_EQUAL, "!=", "!=")
+	case '*':
+		if l.matchNext('*') {
+			l.addSymbol(token.DOUBLE_STAR)
 		} else {
-			l.AddToken(token.BANG, char, char)
+			l.addSymbol(token.STAR)
 		}
-	case "=":
-		canInsertSemi = false
-		if l.matchNext(">") {
-			l.AddToken(token.DOUBLE_ARROW, "=>", "=>")
-		} else if l.matchNext("=") {
-			l.AddToken(token.DOUBLE_EQUAL, "==", "==")
+	case '%':
+		l.addSymbol(token.PERCENT)
+	case '~':
+		if l.matchNext('/') {
+			l.addSymbol(token.TILDE_SLASH)
 		} else {
-			l.AddToken(token.EQUAL, char, char)
+			l.addSymbol(token.TILDE)
 		}
-	case "<":
-		canInsertSemi = false
-		if l.matchNext("=") {
-			l.AddToken(token.LESS_EQUAL, "<=", "<=")
+	case '^':
+		l.addSymbol(token.CARET)
+	case '/':
+		if l.matchNext('/') {
+			l.skipLineComment()
+		} else if l.matchNext('*') {
+			l.skipBlockComment()
 		} else {
-			l.AddToken(token.LESS, char, char)
+			l.addSymbol(token.SLASH)
 		}
-	case ">":
-		canInsertSemi = false
-		if l.matchNext("=") {
-			l.AddToken(token.GREATER_EQUAL, ">=", ">=")
-		} else if l.matchNext(">") {
-			l.AddToken(token.RETURN, ">>", ">>")
+	case '?':
+		l.addSymbol(token.QUESTION)
+	case '!':
+		if l.matchNext('=') {
+			l.addSymbol(token.BANG_EQUAL)
 		} else {
-			l.AddToken(token.GREATER, char, char)
+			l.addSymbol(token.BANG)
 		}
-	case ":":
-		canInsertSemi = false
-		l.AddToken(token.COLON, char, char)
-	case "\"":
-		canInsertSemi = false
-		l.matchString()
-		// canInsertSemi = true
-	case "\n":
-		canInsertSemi = false
-		// canInsertSemi = false
-		// l.AddToken(token.SEMICOLON, ";", "\\n")
-		l.Line++
-	case " ", "\r", "\t":
+	case '=':
+		if l.matchNext('>') {
+			l.addSymbol(token.DOUBLE_ARROW)
+		} else if l.matchNext('=') {
+			l.addSymbol(token.DOUBLE_EQUAL)
+		} else {
+			l.addSymbol(token.EQUAL)
+		}
+	case '<':
+		if l.matchNext('=') {
+			l.addSymbol(token.LESS_EQUAL)
+		} else if l.matchNext('<') {
+			l.addSymbol(token.SHIFT_LEFT)
+		} else {
+			l.addSymbol(token.LESS)
+		}
+	case '>':
+		if l.matchNext('=') {
+			l.addSymbol(token.GREATER_EQUAL)
+		} else if l.matchNext('>') {
+			if l.matchNext('>') {
+				l.addSymbol(token.SHIFT_RIGHT)
+			} else {
+				l.addSymbol(token.RETURN)
+			}
+		} else {
+			l.addSymbol(token.GREATER)
+		}
+	case '&':
+		if l.matchNext('&') {
+			l.addSymbol(token.AND)
+		} else {
+			l.addSymbol(token.AMPERSAND)
+		}
+	case '|':
+		if l.matchNext('|') {
+			l.addSymbol(token.OR)
+		} else {
+			l.addSymbol(token.PIPE)
+		}
+	case ':':
+		l.addSymbol(token.COLON)
+	case '"', '\'':
+		l.matchString(char)
+	case '\n', ' ', '\r', '\t':
 		break
 	default:
-		canInsertSemi = false
 		if isDigit(char) {
 			l.matchNumber()
 		} else if isLetter(char) {
 			l.matchIdentifier()
 		} else {
-			return fmt.Errorf("unsupported type: %s", char)
+			l.error(errors.UnsupportedCharacter, fmt.Sprintf("unsupported character '%c'", char))
+			l.addIllegal()
 		}
 	}
 	l.insertSemi = canInsertSemi
-	return nil
 }
 
-func (l *Lexer) NextChar() string {
-	char := string(l.Input[l.Current])
-	l.Current++
+// NextChar decodes the rune at the current offset and moves past it
+func (l *Lexer) NextChar() rune {
+	char, size := utf8.DecodeRuneInString(l.Input[l.Current:])
+	l.Current += size
+	if char == '\n' {
+		l.Line++
+		l.Column = 1
+	} else {
+		l.Column++
+	}
 	return char
 }
 
@@ -171,10 +250,66 @@ func (l *Lexer) AddToken(tokenType token.TokenType, text string, literal interfa
 		Text:     text,
 		Literal:  literal,
 		Position: l.Start,
-		Line:     l.Line,
+		Line:     l.StartLine,
+		Column:   l.StartColumn,
+		End: token.Pos{
+			Offset: l.Current,
+			Line:   l.Line,
+			Column: l.Column,
+		},
 	})
 }
 
+// symbols are their own text and literal, so the text is sliced from the input
+// and the literal comes from symbolLiterals rather than being allocated for every token
+func (l *Lexer) addSymbol(tokenType token.TokenType) {
+	l.AddToken(tokenType, l.Input[l.Start:l.Current], symbolLiterals[tokenType])
+}
+
+// input that has been reported as an error is still added as a token, so that the parser
+// knows there's something there and doesn't report the gap as an error of its own
+func (l *Lexer) addIllegal() {
+	l.AddToken(token.ILLEGAL, l.Input[l.Start:l.Current], nil)
+}
+
+var symbolLiterals = [...]interface{}{
+	token.LEFT_PAREN:    "(",
+	token.RIGHT_PAREN:   ")",
+	token.LEFT_BRACE:    "{",
+	token.RIGHT_BRACE:   "}",
+	token.COMMA:         ",",
+	token.DOT:           ".",
+	token.COLON:         ":",
+	token.PLUS:          "+",
+	token.MINUS:         "-",
+	token.STAR:          "*",
+	token.SLASH:         "/",
+	token.PERCENT:       "%",
+	token.AMPERSAND:     "&",
+	token.PIPE:          "|",
+	token.CARET:         "^",
+	token.TILDE:         "~",
+	token.QUESTION:      "?",
+	token.DOUBLE_ARROW:  "=>",
+	token.DOUBLE_EQUAL:  "==",
+	token.BANG:          "!",
+	token.BANG_EQUAL:    "!=",
+	token.EQUAL:         "=",
+	token.GREATER:       ">",
+	token.LESS:          "<",
+	token.GREATER_EQUAL: ">=",
+	token.LESS_EQUAL:    "<=",
+	token.INCREMENT:     "++",
+	token.DECREMENT:     "--",
+	token.RETURN:        ">>",
+	token.DOUBLE_STAR:   "**",
+	token.TILDE_SLASH:   "~/",
+	token.SHIFT_LEFT:    "<<",
+	token.SHIFT_RIGHT:   ">>>",
+	token.AND:           "&&",
+	token.OR:            "||",
+}
+
 func (l *Lexer) TokenTypes() []token.TokenType {
 	var types []token.TokenType
 	for _, token := range l.Tokens {
@@ -187,98 +322,372 @@ func (l *Lexer) isAtEnd() bool {
 	return l.Current >= len(l.Input)
 }
 
-func (l *Lexer) peek() string {
+func (l *Lexer) peek() rune {
+	return l.peekAt(l.Current)
+}
+
+func (l *Lexer) peekNext() rune {
 	if l.isAtEnd() {
-		return ""
+		return eof
 	}
+	_, size := utf8.DecodeRuneInString(l.Input[l.Current:])
+	return l.peekAt(l.Current + size)
+}
 
-	return string(l.Input[l.Current])
+func (l *Lexer) peekAt(offset int) rune {
+	if offset >= len(l.Input) {
+		return eof
+	}
+	if b := l.Input[offset]; b < utf8.RuneSelf {
+		return rune(b)
+	}
+	char, _ := utf8.DecodeRuneInString(l.Input[offset:])
+	return char
 }
 
-func (l *Lexer) peekNext() string {
-	if l.Current+1 >= len(l.Input) {
-		return ""
+// peek at the next character that isn't whitespace or part of a comment
+func (l *Lexer) peekSignificant() rune {
+	for offset := l.Current; offset < len(l.Input); offset++ {
+		rest := l.Input[offset:]
+		if strings.HasPrefix(rest, "//") {
+			end := strings.IndexByte(rest, '\n')
+			if end == -1 {
+				return eof
+			}
+			offset += end
+			continue
+		}
+		if strings.HasPrefix(rest, "/*") {
+			end := strings.Index(rest[2:], "*/")
+			if end == -1 {
+				return eof
+			}
+			offset += end + 3
+			continue
+		}
+
+		switch char := l.peekAt(offset); char {
+		case ' ', '\r', '\t', '\n':
+		default:
+			return char
+		}
 	}
-	return string(l.Input[l.Current+1])
+	return eof
 }
 
+// numbers are decimal ints or floats with an optional fraction and exponent, or ints in hex (0xff),
+// binary (0b1010) or octal (0o17). Digits can be separated with underscores, e.g. 1_000_000.
+// A malformed number is still added as a token so that the parser doesn't trip over the gap.
 func (l *Lexer) matchNumber() {
-	for isDigit(l.peek()) {
+	if l.Input[l.Start] == '0' && isBasePrefix(l.peek()) {
 		l.NextChar()
+		// take everything up to the end of the word so that bad digits are reported rather than split off
+		for isAlphaNumeric(l.peek()) {
+			l.NextChar()
+		}
+		l.addInt(l.Input[l.Start:l.Current])
+		return
 	}
 
-	if l.peek() == "." && isDigit(l.peekNext()) {
+	isFloat := false
+	l.matchDigits()
+
+	if l.peek() == '.' && isDigit(l.peekNext()) {
+		isFloat = true
 		l.NextChar()
+		l.matchDigits()
+	}
 
-		for isDigit(l.peek()) {
+	if next := l.peek(); next == 'e' || next == 'E' {
+		isFloat = true
+		l.NextChar()
+		if sign := l.peek(); sign == '+' || sign == '-' {
 			l.NextChar()
 		}
+		l.matchDigits()
+	}
+
+	malformed := false
+	for isAlphaNumeric(l.peek()) {
+		malformed = true
+		l.NextChar()
 	}
 
 	text := l.Input[l.Start:l.Current]
+	if malformed {
+		l.error(errors.MalformedNumber, fmt.Sprintf("malformed number literal '%s'", text))
+		l.AddToken(token.NUMBER, text, 0)
+		return
+	}
 
-	var val interface{}
-	if strings.Contains(text, ".") {
-		val, _ = strconv.ParseFloat(text, 64)
-	} else {
-		intVal, _ := strconv.ParseInt(text, 10, 0)
-		val = int(intVal)
+	if !isFloat {
+		if len(text) > 1 && text[0] == '0' {
+			l.error(errors.LeadingZeros, fmt.Sprintf("leading zeros in number literal '%s', use the 0o prefix for octal", text))
+			l.AddToken(token.NUMBER, text, 0)
+			return
+		}
+		l.addInt(text)
+		return
 	}
 
+	val, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		l.numberError(text, err)
+	}
 	l.AddToken(token.NUMBER, text, val)
 }
 
-func (l *Lexer) matchString() {
-	for l.peek() != "\"" && !l.isAtEnd() {
+// base 0 has strconv work out the base from the prefix and check the underscores are between digits
+func (l *Lexer) addInt(text string) {
+	val, err := strconv.ParseInt(text, 0, 0)
+	if err != nil {
+		l.numberError(text, err)
+	}
+	l.AddToken(token.NUMBER, text, int(val))
+}
+
+func (l *Lexer) numberError(text string, err error) {
+	if stderrors.Is(err, strconv.ErrRange) {
+		l.error(errors.NumberOutOfRange, fmt.Sprintf("number literal '%s' is out of range", text))
+	} else {
+		l.error(errors.MalformedNumber, fmt.Sprintf("malformed number literal '%s'", text))
+	}
+}
+
+func (l *Lexer) matchDigits() {
+	for isDigit(l.peek()) || l.peek() == '_' {
 		l.NextChar()
 	}
+}
+
+// strings can be quoted with either " or ', and the literal holds the string with its escapes applied.
+// A string containing ${...} is split into an INTERPOLATION token for each part before an expression,
+// then the tokens of the expression, and finally a STRING token for the rest.
+func (l *Lexer) matchString(quote rune) {
+	start := l.Current
+
+	// the literal is only built up once there's an escape, otherwise it's sliced from the input
+	var value strings.Builder
+	escaped := false
+	segment := start
+
+	for l.peek() != quote {
+		if l.isAtEnd() {
+			l.error(errors.UnterminatedString, "unterminated string")
+			l.addIllegal()
+			return
+		}
 
+		offset := l.Current
+		switch l.NextChar() {
+		case '\\':
+			escaped = true
+			value.WriteString(l.Input[segment:offset])
+			l.matchEscape(&value, offset)
+			segment = l.Current
+		case '$':
+			if l.matchNext('{') {
+				l.AddToken(token.INTERPOLATION, l.Input[start:offset], l.stringLiteral(&value, escaped, segment, offset))
+				l.interpolations = append(l.interpolations, interpolation{quote: quote, token: len(l.Tokens) - 1})
+				return
+			}
+		}
+	}
+
+	end := l.Current
 	l.NextChar()
-	text := l.Input[l.Start+1 : l.Current-1]
-	l.AddToken(token.STRING, text, text)
+	l.AddToken(token.STRING, l.Input[start:end], l.stringLiteral(&value, escaped, segment, end))
+}
+
+func (l *Lexer) stringLiteral(value *strings.Builder, escaped bool, segment, end int) string {
+	if !escaped {
+		return l.Input[segment:end]
+	}
+	value.WriteString(l.Input[segment:end])
+	return value.String()
+}
+
+// a bad escape is reported at the backslash, which is at offset, and the rest of the string is still scanned.
+// A backslash at the end of the input is left for matchString to report as an unterminated string.
+func (l *Lexer) matchEscape(value *strings.Builder, offset int) {
+	if l.isAtEnd() {
+		return
+	}
+
+	line, column := l.Line, l.Column-1
+	char := l.NextChar()
+	switch char {
+	case 'n':
+		value.WriteByte('\n')
+	case 't':
+		value.WriteByte('\t')
+	case '\\', '"', '\'', '$':
+		value.WriteRune(char)
+	case 'u':
+		r, message := l.matchUnicodeEscape()
+		if message != "" {
+			l.errorAt(offset, line, column, errors.InvalidUnicodeEscape, message)
+			return
+		}
+		value.WriteRune(r)
+	default:
+		l.errorAt(offset, line, column, errors.UnknownEscape, fmt.Sprintf("unknown escape sequence '\\%c'", char))
+	}
+}
+
+// matches the {...} part of a \u{...} escape, which holds the code point in hex.
+// If the escape is invalid the message describing why is returned instead.
+func (l *Lexer) matchUnicodeEscape() (rune, string) {
+	if !l.matchNext('{') {
+		return 0, "expected '{' after '\\u'"
+	}
+
+	start := l.Current
+	for l.peek() != '}' {
+		if l.isAtEnd() || l.peek() == '\n' {
+			return 0, "unterminated unicode escape"
+		}
+		l.NextChar()
+	}
+	digits := l.Input[start:l.Current]
+	l.NextChar()
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+		return 0, fmt.Sprintf("invalid unicode escape '\\u{%s}'", digits)
+	}
+	return rune(code), ""
 }
 
 func (l *Lexer) matchIdentifier() {
-	for isAlphaNumeric(l.peek()) && !l.isAtEnd() {
+	for isAlphaNumeric(l.peek()) {
 		l.NextChar()
 	}
 
 	text := l.Input[l.Start:l.Current]
-	if tokenType, ok := token.Keywords()[text]; ok {
+	if tokenType, ok := token.LookupKeyword(text); ok {
 		l.AddToken(tokenType, text, text)
 	} else {
 		l.AddToken(token.IDENTIFIER, text, text)
 	}
 }
 
-func (l *Lexer) matchNext(expected string) bool {
-	if string(l.Input[l.Current]) != expected {
+// the newline is left for ReadChar so that it's skipped like any other whitespace
+func (l *Lexer) skipLineComment() {
+	for l.peek() != '\n' && !l.isAtEnd() {
+		l.NextChar()
+	}
+}
+
+func (l *Lexer) skipBlockComment() {
+	for !l.isAtEnd() {
+		if l.NextChar() == '*' && l.matchNext('/') {
+			return
+		}
+	}
+	l.error(errors.UnterminatedComment, "unterminated block comment")
+	l.addIllegal()
+}
+
+func (l *Lexer) matchNext(expected rune) bool {
+	if l.peek() != expected {
 		return false
 	}
 	l.NextChar()
 	return true
 }
 
+// report a problem with the token being scanned
+func (l *Lexer) error(code errors.Code, message string) {
+	l.errorAt(l.Start, l.StartLine, l.StartColumn, code, message)
+}
+
+// the error runs from the given position up to the end of what's been scanned
+func (l *Lexer) errorAt(position, line, column int, code errors.Code, message string) {
+	l.Errors = append(l.Errors, &LexError{
+		Code:     code,
+		Message:  message,
+		Position: position,
+		Line:     line,
+		Column:   column,
+		End: token.Pos{
+			Offset: l.Current,
+			Line:   l.Line,
+			Column: l.Column,
+		},
+	})
+}
+
 func (l *Lexer) PrintTokens() {
 	for _, token := range l.Tokens {
 		fmt.Printf("%+v \n", token)
 	}
 }
 
-func isDigit(ch string) bool {
-	_, err := strconv.Atoi(ch)
-	return err == nil
+var _ errors.Error = (*LexError)(nil)
+
+type LexError struct {
+	Code     errors.Code
+	Message  string
+	Position int
+	Line     int
+	Column   int
+	End      token.Pos
 }
 
-func isLetter(ch string) bool {
-	for _, r := range ch {
-		if !unicode.IsLetter(r) {
-			return false
-		}
+func (le *LexError) Error() string {
+	return fmt.Sprintf("[line %d] Error: %s", le.Line, le.Message)
+}
+
+func (le *LexError) ErrorCode() errors.Code {
+	return le.Code
+}
+
+func (le *LexError) Diagnostic() diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Kind:    "lex error",
+		Code:    string(le.Code),
+		Message: le.Message,
+		Span: token.Span{
+			Start: token.Pos{Offset: le.Position, Line: le.Line, Column: le.Column},
+			End:   le.End,
+		},
 	}
-	return true
 }
 
-func isAlphaNumeric(ch string) bool {
-	return isDigit(ch) || isLetter(ch)
+// ErrorList is returned by Lex when there's at least one error
+type ErrorList []*LexError
+
+func (el ErrorList) Error() string {
+	messages := make([]string, len(el))
+	for index, err := range el {
+		messages[index] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the first error, so that errors.As can find a *LexError
+func (el ErrorList) Unwrap() error {
+	return el[0]
+}
+
+func isBasePrefix(ch rune) bool {
+	switch ch {
+	case 'x', 'X', 'b', 'B', 'o', 'O':
+		return true
+	}
+	return false
+}
+
+func isDigit(ch rune) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+// identifiers can use letters from any script, as well as underscores
+func isLetter(ch rune) bool {
+	return ch == '_' || unicode.IsLetter(ch)
+}
+
+func isAlphaNumeric(ch rune) bool {
+	return isLetter(ch) || isDigit(ch) || unicode.IsDigit(ch) || unicode.Is(unicode.Mn, ch)
 }
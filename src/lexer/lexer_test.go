@@ -1,9 +1,14 @@
 package lexer_test
 
 import (
+	stderrors "errors"
+	"strings"
 	"testing"
+	"tim/errors"
 	"tim/lexer"
 	"tim/token"
+
+	"github.com/stretchr/testify/assert"
 )
 
 type TokenCase struct {
@@ -51,6 +56,70 @@ func TestLexer(t *testing.T) {
 				token.EOF,
 			},
 		},
+		"logical operators": {
+			InputString: "(!a && b || c)",
+			Types: []token.TokenType{
+				token.LEFT_PAREN,
+				token.BANG,
+				token.IDENTIFIER,
+				token.AND,
+				token.IDENTIFIER,
+				token.OR,
+				token.IDENTIFIER,
+				token.RIGHT_PAREN,
+				token.SEMICOLON,
+				token.EOF,
+			},
+		},
+		"arithmetic operators": {
+			InputString: "(a % b ~/ c ** d * e)",
+			Types: []token.TokenType{
+				token.LEFT_PAREN,
+				token.IDENTIFIER,
+				token.PERCENT,
+				token.IDENTIFIER,
+				token.TILDE_SLASH,
+				token.IDENTIFIER,
+				token.DOUBLE_STAR,
+				token.IDENTIFIER,
+				token.STAR,
+				token.IDENTIFIER,
+				token.RIGHT_PAREN,
+				token.SEMICOLON,
+				token.EOF,
+			},
+		},
+		"bitwise operators": {
+			InputString: "(~a & b | c ^ d << 1 >>> 2)",
+			Types: []token.TokenType{
+				token.LEFT_PAREN,
+				token.TILDE,
+				token.IDENTIFIER,
+				token.AMPERSAND,
+				token.IDENTIFIER,
+				token.PIPE,
+				token.IDENTIFIER,
+				token.CARET,
+				token.IDENTIFIER,
+				token.SHIFT_LEFT,
+				token.NUMBER,
+				token.SHIFT_RIGHT,
+				token.NUMBER,
+				token.RIGHT_PAREN,
+				token.SEMICOLON,
+				token.EOF,
+			},
+		},
+		"return is still two characters": {
+			InputString: "{ >> a }",
+			Types: []token.TokenType{
+				token.LEFT_BRACE,
+				token.RETURN,
+				token.IDENTIFIER,
+				token.RIGHT_BRACE,
+				token.EOF,
+			},
+		},
 		"function": {
 			InputString: "(addOne: (myNumber) => { >> myNumber + 1 })",
 			Types: []token.TokenType{
@@ -114,6 +183,59 @@ func TestLexer(t *testing.T) {
 				token.EOF,
 			},
 		},
+		"string interpolation": {
+			InputString: `("a ${b} c ${ {} } d")`,
+			Types: []token.TokenType{
+				token.LEFT_PAREN,
+				token.INTERPOLATION,
+				token.IDENTIFIER,
+				token.INTERPOLATION,
+				token.LEFT_BRACE,
+				token.RIGHT_BRACE,
+				token.STRING,
+				token.RIGHT_PAREN,
+				token.SEMICOLON,
+				token.EOF,
+			},
+		},
+		"line comment": {
+			InputString: "// a comment\n(five: 5) // another comment",
+			Types: []token.TokenType{
+				token.LEFT_PAREN,
+				token.IDENTIFIER,
+				token.COLON,
+				token.NUMBER,
+				token.RIGHT_PAREN,
+				token.SEMICOLON,
+				token.EOF,
+			},
+		},
+		"block comment": {
+			InputString: "(five: /* a\nblock comment */ 5)",
+			Types: []token.TokenType{
+				token.LEFT_PAREN,
+				token.IDENTIFIER,
+				token.COLON,
+				token.NUMBER,
+				token.RIGHT_PAREN,
+				token.SEMICOLON,
+				token.EOF,
+			},
+		},
+		"comment between list and function": {
+			InputString: "(5) /* comment */ .print()",
+			Types: []token.TokenType{
+				token.LEFT_PAREN,
+				token.NUMBER,
+				token.RIGHT_PAREN,
+				token.DOT,
+				token.IDENTIFIER,
+				token.LEFT_PAREN,
+				token.RIGHT_PAREN,
+				token.SEMICOLON,
+				token.EOF,
+			},
+		},
 	}
 
 	for name, testcase := range cases {
@@ -126,6 +248,201 @@ func TestLexer(t *testing.T) {
 	}
 }
 
+func TestStringLiterals(t *testing.T) {
+	cases := map[string]struct {
+		InputString string
+		Literal     string
+	}{
+		"double quoted":        {InputString: `"hello"`, Literal: "hello"},
+		"single quoted":        {InputString: `'hello'`, Literal: "hello"},
+		"other quote inside":   {InputString: `'say "hi"'`, Literal: `say "hi"`},
+		"newline and tab":      {InputString: `"a\nb\tc"`, Literal: "a\nb\tc"},
+		"escaped backslash":    {InputString: `"a\\b"`, Literal: `a\b`},
+		"escaped double quote": {InputString: `"say \"hi\""`, Literal: `say "hi"`},
+		"escaped single quote": {InputString: `'don\'t'`, Literal: "don't"},
+		"unicode escape":       {InputString: `"caf\u{e9} \u{1F600}"`, Literal: "café 😀"},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := lexer.New(testcase.InputString)
+			assert.Equal(t, token.STRING, l.Tokens[0].Type)
+			assert.Equal(t, testcase.Literal, l.Tokens[0].Literal)
+		})
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	cases := map[string]struct {
+		InputString string
+		Err         string
+	}{
+		"unterminated":               {InputString: "(\n\"hello)", Err: "[line 2] Error: unterminated string"},
+		"unterminated by escape":     {InputString: `"hello\`, Err: "[line 1] Error: unterminated string"},
+		"unknown escape":             {InputString: `"\q"`, Err: "[line 1] Error: unknown escape sequence '\\q'"},
+		"unicode without brace":      {InputString: `"\u00e9"`, Err: "[line 1] Error: expected '{' after '\\u'"},
+		"unterminated interpolation": {InputString: `"a ${b`, Err: "[line 1] Error: unterminated string interpolation"},
+		"invalid unicode":            {InputString: `"\u{110000}"`, Err: "[line 1] Error: invalid unicode escape '\\u{110000}'"},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := lexer.New(testcase.InputString)
+			assert.Equal(t, []string{testcase.Err}, errorMessages(l))
+		})
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	cases := map[string]struct {
+		InputString string
+		Literal     interface{}
+	}{
+		"decimal":               {InputString: "42", Literal: 42},
+		"zero":                  {InputString: "0", Literal: 0},
+		"float":                 {InputString: "200.32", Literal: 200.32},
+		"hex":                   {InputString: "0xff", Literal: 255},
+		"upper case hex":        {InputString: "0XFF", Literal: 255},
+		"binary":                {InputString: "0b1010", Literal: 10},
+		"octal":                 {InputString: "0o17", Literal: 15},
+		"digit separators":      {InputString: "1_000_000", Literal: 1000000},
+		"separator after base":  {InputString: "0x_ff_ff", Literal: 65535},
+		"float with separators": {InputString: "1_000.000_5", Literal: 1000.0005},
+		"exponent":              {InputString: "2e3", Literal: 2000.0},
+		"negative exponent":     {InputString: "1.5e-3", Literal: 0.0015},
+		"positive exponent":     {InputString: "1.5E+3", Literal: 1500.0},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := lexer.New(testcase.InputString)
+			assert.Equal(t, token.NUMBER, l.Tokens[0].Type)
+			assert.Equal(t, testcase.Literal, l.Tokens[0].Literal)
+		})
+	}
+}
+
+func TestNumberErrors(t *testing.T) {
+	cases := map[string]struct {
+		InputString string
+		Err         string
+	}{
+		"prefix without digits":  {InputString: "0x", Err: "[line 1] Error: malformed number literal '0x'"},
+		"invalid hex digit":      {InputString: "0xfg", Err: "[line 1] Error: malformed number literal '0xfg'"},
+		"invalid binary digit":   {InputString: "0b102", Err: "[line 1] Error: malformed number literal '0b102'"},
+		"double separator":       {InputString: "1__0", Err: "[line 1] Error: malformed number literal '1__0'"},
+		"trailing separator":     {InputString: "1_", Err: "[line 1] Error: malformed number literal '1_'"},
+		"letters after number":   {InputString: "12ab", Err: "[line 1] Error: malformed number literal '12ab'"},
+		"exponent without digit": {InputString: "1e", Err: "[line 1] Error: malformed number literal '1e'"},
+		"leading zeros":          {InputString: "007", Err: "[line 1] Error: leading zeros in number literal '007', use the 0o prefix for octal"},
+		"int out of range":       {InputString: "99999999999999999999", Err: "[line 1] Error: number literal '99999999999999999999' is out of range"},
+		"float out of range":     {InputString: "1e400", Err: "[line 1] Error: number literal '1e400' is out of range"},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := lexer.New(testcase.InputString)
+			assert.Equal(t, []string{testcase.Err}, errorMessages(l))
+		})
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	l := lexer.New("(café: \"naïve ☕\", 名前: 1)")
+	assert.Equal(t, []token.TokenType{
+		token.LEFT_PAREN,
+		token.IDENTIFIER,
+		token.COLON,
+		token.STRING,
+		token.COMMA,
+		token.IDENTIFIER,
+		token.COLON,
+		token.NUMBER,
+		token.RIGHT_PAREN,
+		token.SEMICOLON,
+		token.EOF,
+	}, l.TokenTypes())
+	assert.Equal(t, "café", l.Tokens[1].Text)
+	assert.Equal(t, "naïve ☕", l.Tokens[3].Literal)
+	assert.Equal(t, "名前", l.Tokens[5].Text)
+}
+
+func TestPositions(t *testing.T) {
+	l := lexer.New("(café: 1,\n  ü: 2)")
+
+	// byte offset, line and rune column of each token
+	expected := [][3]int{
+		{0, 1, 1},  // (
+		{1, 1, 2},  // café
+		{6, 1, 6},  // :
+		{8, 1, 8},  // 1
+		{9, 1, 9},  // ,
+		{13, 2, 3}, // ü
+		{15, 2, 4}, // :
+		{17, 2, 6}, // 2
+		{18, 2, 7}, // )
+		{18, 2, 7}, // ;
+		{19, 2, 8}, // EOF
+	}
+	var actual [][3]int
+	for _, tok := range l.Tokens {
+		actual = append(actual, [3]int{tok.Position, tok.Line, tok.Column})
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestBlockCommentLines(t *testing.T) {
+	l := lexer.New("/* one\ntwo\nthree */ five")
+	assert.Equal(t, 3, l.Tokens[0].Line)
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	l := lexer.New("(five: 5)\n/* never closed\n")
+	assert.Equal(t, []string{"[line 2] Error: unterminated block comment"}, errorMessages(l))
+}
+
+func TestMultipleErrors(t *testing.T) {
+	l := lexer.New("(a: 1 @ 2,\n  b: 0xz,\n  c: \"\\q and \\u{zz}\",\n  d: #)")
+	assert.Equal(t, []string{
+		"[line 1] Error: unsupported character '@'",
+		"[line 2] Error: malformed number literal '0xz'",
+		"[line 3] Error: unknown escape sequence '\\q'",
+		"[line 3] Error: invalid unicode escape '\\u{zz}'",
+		"[line 4] Error: unsupported character '#'",
+	}, errorMessages(l))
+	assert.Equal(t, []errors.Code{
+		errors.UnsupportedCharacter,
+		errors.MalformedNumber,
+		errors.UnknownEscape,
+		errors.InvalidUnicodeEscape,
+		errors.UnsupportedCharacter,
+	}, errorCodes(l))
+
+	// scanning carries on after each error, so the rest of the input is still tokenised
+	assert.Equal(t, token.RIGHT_PAREN, l.Tokens[len(l.Tokens)-3].Type)
+
+	// errors are positioned at the start of the offending text
+	assert.Equal(t, [2]int{1, 7}, [2]int{l.Errors[0].Line, l.Errors[0].Column})
+	assert.Equal(t, [2]int{3, 7}, [2]int{l.Errors[2].Line, l.Errors[2].Column})
+	assert.Equal(t, [2]int{3, 14}, [2]int{l.Errors[3].Line, l.Errors[3].Column})
+}
+
+func errorCodes(l lexer.Lexer) []errors.Code {
+	var codes []errors.Code
+	for _, err := range l.Errors {
+		codes = append(codes, err.Code)
+	}
+	return codes
+}
+
+func errorMessages(l lexer.Lexer) []string {
+	var messages []string
+	for _, err := range l.Errors {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
 func slicesMatch(a []token.TokenType, b []token.TokenType) bool {
 	if len(a) != len(b) {
 		return false
@@ -139,3 +456,88 @@ func slicesMatch(a []token.TokenType, b []token.TokenType) bool {
 	}
 	return true
 }
+
+func BenchmarkLexer(b *testing.B) {
+	input := strings.Repeat(`(add: (x, y) => {
+	>> x + y // add them up
+})
+(five: 5, ten: 10.5, name: "tim ${five}")
+(five, ten).call(add).print()
+`, 1000)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	for i := 0; i < b.N; i++ {
+		lexer.New(input)
+	}
+}
+
+func TestLex(t *testing.T) {
+	tokens, err := lexer.Lex("(1, 2)")
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 7)
+
+	tokens, err = lexer.Lex("(1 @ 2 #)")
+	assert.EqualError(t, err, "[line 1] Error: unsupported character '@'\n[line 1] Error: unsupported character '#'")
+	assert.Len(t, tokens, 8)
+
+	var list lexer.ErrorList
+	assert.True(t, stderrors.As(err, &list))
+	assert.Len(t, list, 2)
+
+	var lexErr *lexer.LexError
+	assert.True(t, stderrors.As(err, &lexErr))
+	assert.Equal(t, 4, lexErr.Column)
+}
+
+func TestIllegalTokens(t *testing.T) {
+	cases := map[string]struct {
+		InputString string
+		TokenTypes  []token.TokenType
+		// the text of the ILLEGAL token
+		Text string
+	}{
+		"unsupported character": {
+			InputString: "(1 @ 2)",
+			TokenTypes:  []token.TokenType{token.LEFT_PAREN, token.NUMBER, token.ILLEGAL, token.NUMBER, token.RIGHT_PAREN, token.SEMICOLON, token.EOF},
+			Text:        "@",
+		},
+		"unterminated string": {
+			InputString: "(a: \"hello)",
+			TokenTypes:  []token.TokenType{token.LEFT_PAREN, token.IDENTIFIER, token.COLON, token.ILLEGAL, token.EOF},
+			Text:        "\"hello)",
+		},
+		"unterminated comment": {
+			InputString: "(1) /* a",
+			TokenTypes:  []token.TokenType{token.LEFT_PAREN, token.NUMBER, token.RIGHT_PAREN, token.SEMICOLON, token.ILLEGAL, token.EOF},
+			Text:        "/* a",
+		},
+		"unterminated interpolation": {
+			InputString: "(\"total: ${total)",
+			TokenTypes:  []token.TokenType{token.LEFT_PAREN, token.ILLEGAL, token.EOF},
+			Text:        "\"total: ${total)",
+		},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := lexer.New(testcase.InputString)
+			assert.Len(t, l.Errors, 1)
+			assert.Equal(t, testcase.TokenTypes, l.TokenTypes())
+			for _, tok := range l.Tokens {
+				if tok.Type == token.ILLEGAL {
+					assert.Equal(t, testcase.Text, tok.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestKeywordsCannotBeChanged(t *testing.T) {
+	keywords := token.Keywords()
+	keywords["maybe"] = token.TRUE
+	delete(keywords, "nil")
+
+	l := lexer.New("maybe nil")
+	assert.Equal(t, []token.TokenType{token.IDENTIFIER, token.NIL, token.EOF}, l.TokenTypes())
+}
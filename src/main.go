@@ -1,41 +1,363 @@
 package main
 
 import (
+	stderrors "errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
+	"tim/diagnostics"
+	"tim/errors"
 	"tim/interpreter"
 	"tim/lexer"
 	"tim/parser"
+	"tim/resolver"
+	"tim/token"
+	"tim/tree"
+)
+
+const usage = `usage: tim <command> [flags] <file>
+
+commands:
+  run     execute a tim program
+  check   report errors in a tim program without running it
+  tokens  print the tokens produced by the lexer
+  ast     print the statements produced by the parser
+  repl    start an interactive session
+  explain describe an error code, e.g. tim explain T2003
+
+pass "-" as the file to read the program from stdin
+`
+
+// exit codes borrowed from sysexits.h
+const (
+	exitUsage    = 64
+	exitDataErr  = 65
+	exitSoftware = 70
+	exitIOErr    = 74
 )
 
 func main() {
-	b, err := os.ReadFile("./basic.tim")
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(exitUsage)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "run":
+		os.Exit(runCommand(args))
+	case "check":
+		os.Exit(checkCommand(args))
+	case "tokens":
+		os.Exit(tokensCommand(args))
+	case "ast":
+		os.Exit(astCommand(args))
+	case "repl":
+		os.Exit(replCommand(args))
+	case "explain":
+		os.Exit(explainCommand(args))
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n\n%s", command, usage)
+		os.Exit(exitUsage)
+	}
+}
+
+func runCommand(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	printTokens := flags.Bool("tokens", false, "print the lexer tokens to stderr before running")
+	printAst := flags.Bool("ast", false, "print the parsed statements to stderr before running")
+	strict := strictFlag(flags)
+	format := formatFlag(flags)
+	color := colorFlag(flags)
+	source, code := readSourceArg(flags, args)
+	if code != 0 {
+		return code
+	}
+	printer, code := newPrinter(flags, source, *format, *color)
+	if code != 0 {
+		return code
+	}
+
+	tokens, lexErrs := lex(source)
+	if *printTokens {
+		writeTokens(os.Stderr, tokens)
+	}
+
+	statements, parseErrs := parse(sourceName(flags.Arg(0)), tokens)
+	if errs := append(lexErrs, parseErrs...); len(errs) > 0 {
+		return reportErrors(printer, errs, exitDataErr)
+	}
+	if *printAst {
+		writeStatements(os.Stderr, statements)
+	}
+
+	statements, errs := resolve(statements)
+	if len(errs) > 0 {
+		return reportErrors(printer, errs, exitDataErr)
+	}
+
+	i := interpreter.New()
+	i.Strict = *strict
+	if _, err := i.Interpret(statements); err != nil {
+		return reportError(printer, err, exitSoftware)
+	}
+	return 0
+}
+
+// check runs everything up to the interpreter, so that every error that can be found
+// without running the program is reported
+func checkCommand(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	format := formatFlag(flags)
+	color := colorFlag(flags)
+	source, code := readSourceArg(flags, args)
+	if code != 0 {
+		return code
+	}
+	printer, code := newPrinter(flags, source, *format, *color)
+	if code != 0 {
+		return code
+	}
+
+	if errs := check(sourceName(flags.Arg(0)), source); len(errs) > 0 {
+		return reportErrors(printer, errs, exitDataErr)
+	}
+	return 0
+}
+
+// the resolver runs on whatever statements the parser recovered, even if there were errors
+// before it, and the errors of every stage are returned in the order they appear in the source
+func check(file string, source string) []error {
+	tokens, lexErrs := lex(source)
+	statements, parseErrs := parse(file, tokens)
+	_, resolveErrs := resolve(statements)
+
+	errs := append(append(lexErrs, parseErrs...), resolveErrs...)
+	sort.SliceStable(errs, func(i, j int) bool {
+		return positionOf(errs[i]).Offset < positionOf(errs[j]).Offset
+	})
+	return errs
+}
+
+// errors without a span are sorted before any that have one
+func positionOf(err error) token.Pos {
+	var diagnosable diagnostics.Error
+	if !stderrors.As(err, &diagnosable) {
+		return token.Pos{}
+	}
+	return diagnosable.Diagnostic().Span.Start
+}
+
+func tokensCommand(args []string) int {
+	flags := flag.NewFlagSet("tokens", flag.ContinueOnError)
+	color := colorFlag(flags)
+	source, code := readSourceArg(flags, args)
+	if code != 0 {
+		return code
+	}
+	printer, _ := newPrinter(flags, source, formatText, *color)
+
+	tokens, errs := lex(source)
+	if len(errs) > 0 {
+		return reportErrors(printer, errs, exitDataErr)
+	}
+	writeTokens(os.Stdout, tokens)
+	return 0
+}
+
+func astCommand(args []string) int {
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	color := colorFlag(flags)
+	source, code := readSourceArg(flags, args)
+	if code != 0 {
+		return code
+	}
+	printer, _ := newPrinter(flags, source, formatText, *color)
+
+	tokens, lexErrs := lex(source)
+	statements, parseErrs := parse(sourceName(flags.Arg(0)), tokens)
+	if errs := append(lexErrs, parseErrs...); len(errs) > 0 {
+		return reportErrors(printer, errs, exitDataErr)
+	}
+	writeStatements(os.Stdout, statements)
+	return 0
+}
+
+// explain prints the long form of an error code, or lists every code when none is given
+func explainCommand(args []string) int {
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	switch flags.NArg() {
+	case 0:
+		for _, code := range errors.Codes() {
+			fmt.Printf("%s  %s\n", code, code.Title())
+		}
+		return 0
+	case 1:
+		code, ok := errors.LookupCode(strings.ToUpper(flags.Arg(0)))
+		if !ok {
+			fmt.Fprintf(os.Stderr, "tim explain: unknown error code '%s', run tim explain to list them\n", flags.Arg(0))
+			return exitUsage
+		}
+		fmt.Printf("%s: %s\n\n%s\n", code, code.Title(), code.Explanation())
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "tim explain: expected at most one error code\n\n%s", usage)
+		return exitUsage
+	}
+}
+
+// parses the command's flags and reads the file named by the remaining argument
+func readSourceArg(flags *flag.FlagSet, args []string) (string, int) {
+	flags.SetOutput(os.Stderr)
+	if err := flags.Parse(args); err != nil {
+		return "", exitUsage
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "tim %s: expected exactly one file\n\n%s", flags.Name(), usage)
+		return "", exitUsage
+	}
+
+	source, err := readSource(flags.Arg(0))
 	if err != nil {
-		fmt.Print(err)
+		fmt.Fprintln(os.Stderr, err)
+		return "", exitIOErr
 	}
-	input := string(b)
+	return source, 0
+}
 
-	l := lexer.New(input)
+func sourceName(path string) string {
+	if path == "-" {
+		return "<stdin>"
+	}
+	return path
+}
 
-	fmt.Printf("%+v", l.Tokens)
-	fmt.Println()
+func readSource(path string) (string, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
 
-	p := parser.New(l.Tokens)
-	parsed := p.Parse()
+// each stage carries on after an error, so that all of a stage's errors can be reported at once.
+// The parser still runs when the lexer has errors, to find any errors of its own, and skips
+// over the ILLEGAL tokens the lexer leaves in place of what it couldn't scan.
+func lex(source string) ([]token.Token, []error) {
+	l := lexer.New(source)
+	var errs []error
+	for _, err := range l.Errors {
+		errs = append(errs, err)
+	}
+	return l.Tokens, errs
+}
 
-	fmt.Println()
+// file is recorded in the span of each statement, so that errors can say where they happened
+func parse(file string, tokens []token.Token) ([]tree.Stmt, []error) {
+	p := parser.New(tokens)
+	p.File = file
+	statements := p.Parse()
+	var errs []error
+	for _, err := range p.Errors {
+		errs = append(errs, err)
+	}
+	return statements, errs
+}
 
-	for _, p := range parsed {
-		fmt.Printf("%+v \n\n", p)
+func resolve(statements []tree.Stmt) ([]tree.Stmt, []error) {
+	statements, resolveErrs := resolver.Resolve(statements)
+	var errs []error
+	for _, err := range resolveErrs {
+		errs = append(errs, err)
 	}
+	return statements, errs
+}
 
-	// doesn't work
-	// for _, stmt := range parsed {
-	// 	fmt.Println(tree.Print(stmt))
-	// }
+func strictFlag(flags *flag.FlagSet) *bool {
+	return flags.Bool("strict", false, "only allow booleans as conditions, rather than any truthy value")
+}
 
-	// json, _ := json.Marshal(interpreter.Interpret(parsed, true))
-	// fmt.Println(string(json))
+// colours are on by default when stderr is a terminal, and can be turned off for plain text
+func colorFlag(flags *flag.FlagSet) *bool {
+	return flags.Bool("color", isTerminal(os.Stderr), "colour error messages")
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
 
-	interpreter.Interpret(parsed, true)
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+func formatFlag(flags *flag.FlagSet) *string {
+	return flags.String("format", formatText, "how to write errors, either text or json")
+}
+
+// errorPrinter is implemented by the text and JSON printers in the diagnostics package
+type errorPrinter interface {
+	Print(w io.Writer, err error)
+}
+
+// the text printer shows errors alongside the lines of the program they point at,
+// while the JSON printer writes an object per error for other programs to read
+func newPrinter(flags *flag.FlagSet, source string, format string, color bool) (errorPrinter, int) {
+	file := sourceName(flags.Arg(0))
+	switch format {
+	case formatText:
+		return &diagnostics.Printer{
+			File:   file,
+			Source: source,
+			Color:  color,
+		}, 0
+	case formatJSON:
+		return &diagnostics.JSONPrinter{File: file}, 0
+	default:
+		fmt.Fprintf(os.Stderr, "tim %s: unknown format '%s', expected text or json\n", flags.Name(), format)
+		return nil, exitUsage
+	}
+}
+
+func reportError(printer errorPrinter, err error, code int) int {
+	printer.Print(os.Stderr, err)
+	return code
+}
+
+func reportErrors(printer errorPrinter, errs []error, code int) int {
+	for _, err := range errs {
+		reportError(printer, err, code)
+	}
+	return code
+}
+
+func writeTokens(w io.Writer, tokens []token.Token) {
+	for _, t := range tokens {
+		fmt.Fprintf(w, "%+v\n", t)
+	}
+}
+
+func writeStatements(w io.Writer, statements []tree.Stmt) {
+	for _, statement := range statements {
+		fmt.Fprintf(w, "%+v\n", statement)
+	}
 }
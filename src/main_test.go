@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"tim/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	cases := map[string]struct {
+		InputString string
+		Codes       []errors.Code
+	}{
+		"no errors": {
+			InputString: "(a: 1)\n(a).print()",
+		},
+		"resolves the statements the parser recovered": {
+			InputString: "(a: 1 +)\n(>> 2)",
+			Codes:       []errors.Code{errors.ExpectedExpression, errors.ReturnOutsideFunction},
+		},
+		"in the order they appear": {
+			InputString: "(>> 1)\n(a: 1 +)\n(b: @)",
+			Codes:       []errors.Code{errors.ReturnOutsideFunction, errors.ExpectedExpression, errors.UnsupportedCharacter},
+		},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			var codes []errors.Code
+			for _, err := range check("main.tim", testcase.InputString) {
+				codes = append(codes, err.(errors.Error).ErrorCode())
+			}
+			assert.Equal(t, testcase.Codes, codes)
+		})
+	}
+}
@@ -2,10 +2,24 @@ package parser
 
 import (
 	"fmt"
+	"strings"
+	"tim/diagnostics"
+	"tim/errors"
 	"tim/token"
 	"tim/tree"
 )
 
+// Parse parses the tokens into statements. If there are errors, the statements that could be
+// parsed are returned along with an ErrorList holding every error.
+func Parse(tokens []token.Token) ([]tree.Stmt, error) {
+	parser := New(tokens)
+	statements := parser.Parse()
+	if len(parser.Errors) > 0 {
+		return statements, ErrorList(parser.Errors)
+	}
+	return statements, nil
+}
+
 func New(tokens []token.Token) *Parser {
 	return &Parser{
 		Tokens:  tokens,
@@ -17,21 +31,86 @@ type Parser struct {
 	Tokens            []token.Token
 	Current           int
 	PreviousStatement tree.Stmt
+	// the name of the file the tokens came from, which is recorded in the span of each node
+	File string
+	// every problem found while parsing, the statements they were found in are left out
+	Errors []*ParseError
 }
 
 func (p *Parser) Parse() []tree.Stmt {
 	statements := make([]tree.Stmt, 0)
 	for !p.isAtEnd() {
-		statements = append(statements, p.Declaration())
+		errorCount := len(p.Errors)
+		if stmt := p.safeDeclaration(); stmt != nil {
+			statements = append(statements, stmt)
+		}
+
+		// there's no enclosing list or block at the top level to take a stray closing
+		// bracket that the failed statement stopped at, and it's already been reported
+		if len(p.Errors) > errorCount && (p.check(token.RIGHT_PAREN) || p.check(token.RIGHT_BRACE)) {
+			p.advance()
+			p.expectSemicolon()
+		}
 	}
 	return statements
 }
 
+// safeDeclaration parses a declaration, but if it fails the error is recorded and the
+// tokens up to the next statement or list item are skipped, so that parsing can carry on
+// and find any other errors. It returns nil if the declaration couldn't be parsed.
+func (p *Parser) safeDeclaration() (stmt tree.Stmt) {
+	p.recoverWith(func() {
+		stmt = p.Declaration()
+	})
+	return stmt
+}
+
+func (p *Parser) recoverWith(parse func()) {
+	start := p.Current
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(*ParseError)
+			if !ok {
+				panic(r)
+			}
+			if !p.followsLexError(err.Token) {
+				p.Errors = append(p.Errors, err)
+			}
+			p.synchronise(start)
+
+			// always move on, otherwise the same token would fail again
+			if p.Current == start {
+				p.advance()
+				p.expectSemicolon()
+			}
+		}
+	}()
+	parse()
+}
+
+// the lexer has already reported ILLEGAL tokens, so an error at one isn't reported again.
+// Nor is running out of tokens straight after one that reaches the end of the input, such
+// as an unterminated string, as that's the same problem.
+func (p *Parser) followsLexError(at token.Token) bool {
+	if at.Type == token.ILLEGAL {
+		return true
+	}
+	if at.Type != token.EOF || p.Current == 0 {
+		return false
+	}
+	before := p.Tokens[p.Current-1]
+	return before.Type == token.ILLEGAL && before.End.Offset == at.Position
+}
+
 func (p *Parser) Declaration() tree.Stmt {
 	if p.match(token.LEFT_PAREN) {
 		return p.Iterable()
 	}
 
+	if p.match(token.QUESTION) {
+		return p.Conditional()
+	}
+
 	if p.checkSequence(token.IDENTIFIER, token.COLON) {
 		identifier := p.peek()
 
@@ -53,13 +132,17 @@ func (p *Parser) Declaration() tree.Stmt {
 }
 
 func (p *Parser) Iterable() tree.Stmt {
+	start := p.previous()
+
 	var items []tree.Stmt
 	for !p.check(token.RIGHT_PAREN) && !p.isAtEnd() {
 		if p.check(token.COMMA) {
 			p.advance()
 		}
 
-		items = append(items, p.Declaration())
+		if item := p.safeDeclaration(); item != nil {
+			items = append(items, item)
+		}
 	}
 
 	p.consume(token.RIGHT_PAREN, "expected ')' after expression")
@@ -69,7 +152,7 @@ func (p *Parser) Iterable() tree.Stmt {
 	if p.checkSequence(token.DOUBLE_ARROW, token.LEFT_BRACE) {
 		p.advanceBy(2)
 
-		return p.FunctionDeclaration(items)
+		return p.FunctionDeclaration(start, items)
 	}
 
 	// otherwise this is a list
@@ -79,6 +162,7 @@ func (p *Parser) Iterable() tree.Stmt {
 
 		listFunctions = append(listFunctions, p.Call())
 	}
+	span := p.spanFrom(start)
 
 	if !p.check(token.RIGHT_PAREN) && !p.check(token.COMMA) && !p.check(token.DOT) && !p.check(token.RIGHT_BRACE) {
 		p.consume(token.SEMICOLON, "expected ')'")
@@ -89,11 +173,68 @@ func (p *Parser) Iterable() tree.Stmt {
 	return tree.ListStmt{
 		Items:     items,
 		Functions: listFunctions,
+		Span:      span,
+	}
+}
+
+func (p *Parser) Conditional() tree.Stmt {
+	start := p.previous()
+	p.consume(token.LEFT_PAREN, "expect '(' after '?'")
+
+	var branches []tree.Branch
+	for !p.check(token.RIGHT_PAREN) && !p.isAtEnd() {
+		if p.check(token.COMMA) || p.check(token.SEMICOLON) {
+			p.advance()
+			continue
+		}
+
+		p.recoverWith(func() {
+			branches = append(branches, p.Branch())
+		})
+	}
+
+	p.consume(token.RIGHT_PAREN, "expected ')' after conditional")
+	span := p.spanFrom(start)
+	p.expectSemicolon()
+
+	return tree.ConditionalStmt{
+		Branches: branches,
+		Span:     span,
+	}
+}
+
+func (p *Parser) Branch() tree.Branch {
+	start := p.peek()
+
+	var name token.Token
+	if p.checkSequence(token.IDENTIFIER, token.COLON) {
+		name = p.peek()
+		p.advanceBy(2)
+	}
+
+	p.consume(token.LEFT_PAREN, "expect '(' before condition")
+
+	// empty parentheses are the else branch
+	var condition tree.Expr
+	if !p.check(token.RIGHT_PAREN) {
+		condition = p.Expression()
+	}
+
+	p.consume(token.RIGHT_PAREN, "expect ')' after condition")
+	p.consume(token.DOUBLE_ARROW, "expect '=>' after condition")
+	action := p.Declaration()
+
+	return tree.Branch{
+		Name:      name,
+		Condition: condition,
+		Action:    action,
+		Span:      p.spanFrom(start),
 	}
 }
 
 func (p *Parser) Call() tree.CallStmt {
 	// name of function
+	start := p.peek()
 	callee := p.Primary()
 
 	p.consume(token.LEFT_PAREN, "expect '(' after function declaration")
@@ -113,6 +254,7 @@ func (p *Parser) Call() tree.CallStmt {
 		Callee:       callee,
 		ClosingParen: closingParen,
 		Arguments:    arguments,
+		Span:         p.spanFrom(start),
 	}
 }
 
@@ -122,23 +264,42 @@ func (p *Parser) VarDeclaration(identifier token.Token) tree.Stmt {
 	return tree.VariableStmt{
 		Name:        identifier,
 		Initializer: initializer,
+		Span:        p.spanFrom(identifier),
 	}
 }
 
-func (p *Parser) FunctionDeclaration(arguments []tree.Stmt) tree.Stmt {
+// start is the '(' that opens the arguments
+func (p *Parser) FunctionDeclaration(start token.Token, arguments []tree.Stmt) tree.Stmt {
+	for _, argument := range arguments {
+		if !isParameter(argument) {
+			panic(p.error(p.previous(), errors.InvalidArgumentName, "function arguments must be identifiers"))
+		}
+	}
+
 	body := p.Block()
 
 	return tree.FuncStmt{
 		Body:      body,
 		Arguments: arguments,
+		Span:      p.spanFrom(start),
 	}
 }
 
+func isParameter(stmt tree.Stmt) bool {
+	if exprStmt, ok := stmt.(tree.ExpressionStmt); ok {
+		_, ok := exprStmt.Expr.(tree.Variable)
+		return ok
+	}
+	return false
+}
+
 func (p *Parser) Block() []tree.Stmt {
 	statements := make([]tree.Stmt, 0)
 
 	for !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
-		statements = append(statements, p.Declaration())
+		if stmt := p.safeDeclaration(); stmt != nil {
+			statements = append(statements, stmt)
+		}
 	}
 
 	p.consume(token.RIGHT_BRACE, "expect '}' after block")
@@ -161,29 +322,85 @@ func (p *Parser) ReturnStatement() tree.Stmt {
 	if !p.check(token.SEMICOLON) {
 		value = p.Declaration()
 	}
+	span := p.spanFrom(returnToken)
 
 	p.expectSemicolon()
 
 	return tree.ReturnStmt{
 		Token: returnToken,
 		Value: value,
+		Span:  span,
 	}
 }
 
 func (p *Parser) ExpressionStatement() tree.Stmt {
+	start := p.peek()
 	value := p.Expression()
+	span := p.spanFrom(start)
 	p.expectSemicolon()
 	exprStmt := tree.ExpressionStmt{
 		Expr: value,
+		Span: span,
 	}
 	return exprStmt
 }
 
 func (p *Parser) Expression() tree.Expr {
-	return p.Equality()
+	return p.Assignment()
+}
+
+func (p *Parser) Assignment() tree.Expr {
+	start := p.peek()
+	expr := p.Or()
+
+	if p.match(token.EQUAL) {
+		equals := p.previous()
+		value := p.Assignment()
+
+		if variable, ok := expr.(tree.Variable); ok {
+			return tree.Assign{
+				Name:  variable.Name,
+				Value: value,
+				Span:  p.spanFrom(start),
+			}
+		}
+
+		panic(p.error(equals, errors.InvalidAssignmentTarget, "invalid assignment target"))
+	}
+
+	return expr
+}
+
+func (p *Parser) Or() tree.Expr {
+	start := p.peek()
+	expr := p.And()
+	for p.match(token.OR) {
+		expr = tree.Logical{
+			Left:     expr,
+			Operator: p.previous(),
+			Right:    p.And(),
+			Span:     p.spanFrom(start),
+		}
+	}
+	return expr
+}
+
+func (p *Parser) And() tree.Expr {
+	start := p.peek()
+	expr := p.Equality()
+	for p.match(token.AND) {
+		expr = tree.Logical{
+			Left:     expr,
+			Operator: p.previous(),
+			Right:    p.Equality(),
+			Span:     p.spanFrom(start),
+		}
+	}
+	return expr
 }
 
 func (p *Parser) Equality() tree.Expr {
+	start := p.peek()
 	expr := p.Comparison()
 
 	for p.match(token.DOUBLE_EQUAL, token.BANG_EQUAL) {
@@ -191,6 +408,7 @@ func (p *Parser) Equality() tree.Expr {
 			Left:     expr,
 			Operator: p.previous(),
 			Right:    p.Comparison(),
+			Span:     p.spanFrom(start),
 		}
 	}
 
@@ -198,73 +416,186 @@ func (p *Parser) Equality() tree.Expr {
 }
 
 func (p *Parser) Comparison() tree.Expr {
-	expr := p.Term()
+	start := p.peek()
+	expr := p.BitwiseOr()
 	for p.match(token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL) {
+		expr = tree.Binary{
+			Left:     expr,
+			Operator: p.previous(),
+			Right:    p.BitwiseOr(),
+			Span:     p.spanFrom(start),
+		}
+	}
+	return expr
+}
+
+// the bitwise operators bind tighter than comparisons, so a & mask == 0 compares the result
+// of the &, and go from | loosest, through ^ and &, to the shifts
+func (p *Parser) BitwiseOr() tree.Expr {
+	start := p.peek()
+	expr := p.BitwiseXor()
+	for p.match(token.PIPE) {
+		expr = tree.Binary{
+			Left:     expr,
+			Operator: p.previous(),
+			Right:    p.BitwiseXor(),
+			Span:     p.spanFrom(start),
+		}
+	}
+	return expr
+}
+
+func (p *Parser) BitwiseXor() tree.Expr {
+	start := p.peek()
+	expr := p.BitwiseAnd()
+	for p.match(token.CARET) {
+		expr = tree.Binary{
+			Left:     expr,
+			Operator: p.previous(),
+			Right:    p.BitwiseAnd(),
+			Span:     p.spanFrom(start),
+		}
+	}
+	return expr
+}
+
+func (p *Parser) BitwiseAnd() tree.Expr {
+	start := p.peek()
+	expr := p.Shift()
+	for p.match(token.AMPERSAND) {
+		expr = tree.Binary{
+			Left:     expr,
+			Operator: p.previous(),
+			Right:    p.Shift(),
+			Span:     p.spanFrom(start),
+		}
+	}
+	return expr
+}
+
+func (p *Parser) Shift() tree.Expr {
+	start := p.peek()
+	expr := p.Term()
+	for p.match(token.SHIFT_LEFT, token.SHIFT_RIGHT) {
 		expr = tree.Binary{
 			Left:     expr,
 			Operator: p.previous(),
 			Right:    p.Term(),
+			Span:     p.spanFrom(start),
 		}
 	}
 	return expr
 }
 
 func (p *Parser) Term() tree.Expr {
+	start := p.peek()
 	expr := p.Factor()
 	for p.match(token.MINUS, token.PLUS) {
 		expr = tree.Binary{
 			Left:     expr,
 			Operator: p.previous(),
 			Right:    p.Factor(),
+			Span:     p.spanFrom(start),
 		}
 	}
 	return expr
 }
 
 func (p *Parser) Factor() tree.Expr {
+	start := p.peek()
 	expr := p.Unary()
-	for p.match(token.STAR, token.SLASH) {
+	for p.match(token.STAR, token.SLASH, token.PERCENT, token.TILDE_SLASH) {
 		expr = tree.Binary{
 			Left:     expr,
 			Operator: p.previous(),
 			Right:    p.Unary(),
+			Span:     p.spanFrom(start),
 		}
 	}
 	return expr
 }
 
 func (p *Parser) Unary() tree.Expr {
-	if p.match(token.MINUS) {
+	if p.match(token.BANG, token.MINUS, token.PLUS, token.TILDE) {
+		operator := p.previous()
 		return tree.Unary{
+			Operator: operator,
+			Right:    p.Unary(),
+			Span:     p.spanFrom(operator),
+		}
+	}
+	return p.Exponent()
+}
+
+// ** binds tighter than the unary operators on its left, so -2 ** 2 is -(2 ** 2),
+// and is right-associative, so 2 ** 3 ** 2 is 2 ** (3 ** 2)
+func (p *Parser) Exponent() tree.Expr {
+	start := p.peek()
+	expr := p.Primary()
+	if p.match(token.DOUBLE_STAR) {
+		return tree.Binary{
+			Left:     expr,
 			Operator: p.previous(),
 			Right:    p.Unary(),
+			Span:     p.spanFrom(start),
 		}
 	}
-	return p.Primary()
+	return expr
 }
 
 func (p *Parser) Primary() tree.Expr {
 	if p.match(token.FALSE) {
-		return tree.Literal{Value: false}
+		return tree.Literal{Value: false, Span: p.tokenSpan(p.previous())}
 	}
 	if p.match(token.TRUE) {
-		return tree.Literal{Value: true}
+		return tree.Literal{Value: true, Span: p.tokenSpan(p.previous())}
 	}
 	if p.match(token.NIL) {
-		return tree.Literal{Value: nil}
+		return tree.Literal{Value: nil, Span: p.tokenSpan(p.previous())}
 	}
 	if p.match(token.NUMBER, token.STRING) {
-		return tree.Literal{Value: p.previous().Literal}
+		return tree.Literal{Value: p.previous().Literal, Span: p.tokenSpan(p.previous())}
+	}
+	if p.match(token.INTERPOLATION) {
+		return p.Interpolation()
 	}
 	if p.match(token.IDENTIFIER) {
 		identifier := p.previous()
 
 		// we're using a variable or function, not declaring one
 		if !p.check(token.COLON) {
-			return tree.Variable{Name: identifier}
+			return tree.Variable{Name: identifier, Span: p.tokenSpan(identifier)}
 		}
 	}
-	panic(p.error(p.peek(), "expect expression."))
+	panic(p.error(p.peek(), errors.ExpectedExpression, "expect expression."))
+}
+
+// the lexer splits "a ${b} c" into INTERPOLATION("a "), the tokens of b, then STRING(" c")
+func (p *Parser) Interpolation() tree.Expr {
+	start := p.previous()
+
+	var parts []tree.Expr
+	for {
+		if segment := p.previous(); segment.Literal != "" {
+			parts = append(parts, tree.Literal{Value: segment.Literal, Span: p.tokenSpan(segment)})
+		}
+
+		parts = append(parts, p.Expression())
+
+		if !p.match(token.INTERPOLATION) {
+			break
+		}
+	}
+
+	end := p.consume(token.STRING, "expect end of string after interpolation")
+	if end.Literal != "" {
+		parts = append(parts, tree.Literal{Value: end.Literal, Span: p.tokenSpan(end)})
+	}
+
+	return tree.Interpolation{
+		Parts: parts,
+		Span:  p.spanFrom(start),
+	}
 }
 
 // check that the current token is any of the types and advance if so
@@ -358,7 +689,7 @@ func (p *Parser) consume(tokenType token.TokenType, message string) token.Token
 		return p.advance()
 	}
 
-	panic(p.error(p.peek(), message))
+	panic(p.error(p.peek(), errors.ExpectedToken, message))
 }
 
 func (p *Parser) expectSemicolon() {
@@ -368,40 +699,127 @@ func (p *Parser) expectSemicolon() {
 	}
 }
 
-// func (p *Parser) synchronise() {
-// 	p.advance()
+// skip to the end of the statement or list item that started at the start index and failed
+// to parse. Brackets opened since the start are skipped up to their closing bracket, and a
+// ',', ')' or '}' that belongs to the enclosing list or block is left for it to consume,
+// as is the start of the next declaration.
+func (p *Parser) synchronise(start int) {
+	depth := 0
+	for _, consumed := range p.Tokens[start:p.Current] {
+		switch consumed.Type {
+		case token.LEFT_PAREN, token.LEFT_BRACE:
+			depth++
+		case token.RIGHT_PAREN, token.RIGHT_BRACE:
+			depth--
+		}
+	}
 
-// 	for !p.isAtEnd() {
-// 		if p.previous().Type == token.NEWLINE {
-// 			return
-// 		}
+	for !p.isAtEnd() {
+		switch p.peek().Type {
+		case token.LEFT_PAREN, token.LEFT_BRACE:
+			depth++
+		case token.RIGHT_PAREN, token.RIGHT_BRACE:
+			if depth <= 0 {
+				return
+			}
+			depth--
+		case token.COMMA:
+			if depth == 0 {
+				return
+			}
+		case token.SEMICOLON:
+			if depth == 0 {
+				p.advance()
+				return
+			}
+		case token.IDENTIFIER:
+			// statements aren't always separated, but a name and a colon starts a new declaration
+			if depth == 0 && p.Current > start && p.checkSequence(token.IDENTIFIER, token.COLON) {
+				return
+			}
+		}
+		p.advance()
+	}
+}
 
-// 		switch p.peek().Type {
-// 		case token.LEFT_BRACE: // almost all new statements in timlang begin with a left brace
-// 			return
-// 		}
+// the span from the start of a token up to the end of the last token consumed
+func (p *Parser) spanFrom(start token.Token) token.Span {
+	return token.Span{
+		File:  p.File,
+		Start: start.Start(),
+		End:   p.previous().End,
+	}
+}
 
-// 		p.advance()
-// 	}
-// }
+func (p *Parser) tokenSpan(thisToken token.Token) token.Span {
+	return token.Span{
+		File:  p.File,
+		Start: thisToken.Start(),
+		End:   thisToken.End,
+	}
+}
+
+func (p *Parser) error(thisToken token.Token, code errors.Code, message string) *ParseError {
+	return &ParseError{
+		Code:    code,
+		Message: message,
+		Token:   thisToken,
+		File:    p.File,
+	}
+}
+
+var _ errors.Error = (*ParseError)(nil)
 
-func (p *Parser) error(thisToken token.Token, message string) *ParseError {
+type ParseError struct {
+	Code    errors.Code
+	Message string
+	Token   token.Token
+	File    string
+}
+
+func (pe *ParseError) Error() string {
 	var where string
-	if thisToken.Type == token.EOF {
+	if pe.Token.Type == token.EOF {
 		where = " at end"
 	} else {
-		where = " at '" + thisToken.Text + "'"
+		where = " at '" + pe.Token.Text + "'"
 	}
-	err := &ParseError{
-		Message: fmt.Sprintf("[line %d] Error%s: %s\n", thisToken.Line, where, message),
+	return fmt.Sprintf("[line %d] Error%s: %s\n", pe.Token.Line, where, pe.Message)
+}
+
+func (pe *ParseError) ErrorCode() errors.Code {
+	return pe.Code
+}
+
+func (pe *ParseError) Diagnostic() diagnostics.Diagnostic {
+	diagnostic := diagnostics.Diagnostic{
+		Kind:    "parse error",
+		Code:    string(pe.Code),
+		Message: pe.Message,
+		Span: token.Span{
+			File:  pe.File,
+			Start: pe.Token.Start(),
+			End:   pe.Token.End,
+		},
+	}
+	if pe.Token.Type == token.EOF {
+		diagnostic.Hint = "the program ended early, check for a '(' or '{' that isn't closed"
 	}
-	return err
+	return diagnostic
 }
 
-type ParseError struct {
-	Message string
+// ErrorList is returned by Parse when there's at least one error
+type ErrorList []*ParseError
+
+func (el ErrorList) Error() string {
+	var message strings.Builder
+	for _, err := range el {
+		message.WriteString(err.Error())
+	}
+	return message.String()
 }
 
-func (pe *ParseError) Error() string {
-	return pe.Message
+// Unwrap returns the first error, so that errors.As can find a *ParseError
+func (el ErrorList) Unwrap() error {
+	return el[0]
 }
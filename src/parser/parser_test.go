@@ -1,8 +1,10 @@
 package parser_test
 
 import (
+	stderrors "errors"
 	"reflect"
 	"testing"
+	"tim/errors"
 	"tim/lexer"
 	"tim/parser"
 	"tim/token"
@@ -30,6 +32,7 @@ func TestExpressions(t *testing.T) {
 							Literal:  "==",
 							Position: 2,
 							Line:     1,
+							Column:   3,
 						},
 						Right: tree.Literal{
 							Value: 3,
@@ -52,6 +55,7 @@ func TestExpressions(t *testing.T) {
 							Literal:  ">",
 							Position: 2,
 							Line:     1,
+							Column:   3,
 						},
 						Right: tree.Literal{
 							Value: 2,
@@ -74,6 +78,7 @@ func TestExpressions(t *testing.T) {
 							Literal:  "+",
 							Position: 2,
 							Line:     1,
+							Column:   3,
 						},
 						Right: tree.Literal{
 							Value: 4,
@@ -96,6 +101,7 @@ func TestExpressions(t *testing.T) {
 							Literal:  "*",
 							Position: 2,
 							Line:     1,
+							Column:   3,
 						},
 						Right: tree.Literal{
 							Value: 4,
@@ -115,6 +121,7 @@ func TestExpressions(t *testing.T) {
 							Literal:  "-",
 							Position: 0,
 							Line:     1,
+							Column:   1,
 						},
 						Right: tree.Literal{
 							Value: 4,
@@ -123,6 +130,336 @@ func TestExpressions(t *testing.T) {
 				},
 			},
 		},
+		"assignment": {
+			InputString: "x = 2",
+			Statements: []tree.Stmt{
+				tree.ExpressionStmt{
+					Expr: tree.Assign{
+						Name: token.Token{
+							Type:     token.IDENTIFIER,
+							Text:     "x",
+							Literal:  "x",
+							Position: 0,
+							Line:     1,
+							Column:   1,
+						},
+						Value: tree.Literal{
+							Value: 2,
+						},
+					},
+				},
+			},
+		},
+		"primary: string interpolation": {
+			InputString: `"a ${b} c"`,
+			Statements: []tree.Stmt{
+				tree.ExpressionStmt{
+					Expr: tree.Interpolation{
+						Parts: []tree.Expr{
+							tree.Literal{
+								Value: "a ",
+							},
+							tree.Variable{
+								Name: token.Token{
+									Type:     token.IDENTIFIER,
+									Text:     "b",
+									Literal:  "b",
+									Position: 5,
+									Line:     1,
+									Column:   6,
+								},
+							},
+							tree.Literal{
+								Value: " c",
+							},
+						},
+					},
+				},
+			},
+		},
+		"logical: and binds tighter than or": {
+			InputString: "true || false && !true",
+			Statements: []tree.Stmt{
+				tree.ExpressionStmt{
+					Expr: tree.Logical{
+						Left: tree.Literal{
+							Value: true,
+						},
+						Operator: token.Token{
+							Type:     token.OR,
+							Text:     "||",
+							Literal:  "||",
+							Position: 5,
+							Line:     1,
+							Column:   6,
+						},
+						Right: tree.Logical{
+							Left: tree.Literal{
+								Value: false,
+							},
+							Operator: token.Token{
+								Type:     token.AND,
+								Text:     "&&",
+								Literal:  "&&",
+								Position: 14,
+								Line:     1,
+								Column:   15,
+							},
+							Right: tree.Unary{
+								Operator: token.Token{
+									Type:     token.BANG,
+									Text:     "!",
+									Literal:  "!",
+									Position: 17,
+									Line:     1,
+									Column:   18,
+								},
+								Right: tree.Literal{
+									Value: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"logical: equality binds tighter than and": {
+			InputString: "1 == 1 && 2",
+			Statements: []tree.Stmt{
+				tree.ExpressionStmt{
+					Expr: tree.Logical{
+						Left: tree.Binary{
+							Left: tree.Literal{
+								Value: 1,
+							},
+							Operator: token.Token{
+								Type:     token.DOUBLE_EQUAL,
+								Text:     "==",
+								Literal:  "==",
+								Position: 2,
+								Line:     1,
+								Column:   3,
+							},
+							Right: tree.Literal{
+								Value: 1,
+							},
+						},
+						Operator: token.Token{
+							Type:     token.AND,
+							Text:     "&&",
+							Literal:  "&&",
+							Position: 7,
+							Line:     1,
+							Column:   8,
+						},
+						Right: tree.Literal{
+							Value: 2,
+						},
+					},
+				},
+			},
+		},
+		"exponent: right-associative": {
+			InputString: "2 ** 3 ** 2",
+			Statements: []tree.Stmt{
+				tree.ExpressionStmt{
+					Expr: tree.Binary{
+						Left: tree.Literal{
+							Value: 2,
+						},
+						Operator: token.Token{
+							Type:     token.DOUBLE_STAR,
+							Text:     "**",
+							Literal:  "**",
+							Position: 2,
+							Line:     1,
+							Column:   3,
+						},
+						Right: tree.Binary{
+							Left: tree.Literal{
+								Value: 3,
+							},
+							Operator: token.Token{
+								Type:     token.DOUBLE_STAR,
+								Text:     "**",
+								Literal:  "**",
+								Position: 7,
+								Line:     1,
+								Column:   8,
+							},
+							Right: tree.Literal{
+								Value: 2,
+							},
+						},
+					},
+				},
+			},
+		},
+		"factor: exponent binds tighter than modulo": {
+			InputString: "4 % 3 ** 2",
+			Statements: []tree.Stmt{
+				tree.ExpressionStmt{
+					Expr: tree.Binary{
+						Left: tree.Literal{
+							Value: 4,
+						},
+						Operator: token.Token{
+							Type:     token.PERCENT,
+							Text:     "%",
+							Literal:  "%",
+							Position: 2,
+							Line:     1,
+							Column:   3,
+						},
+						Right: tree.Binary{
+							Left: tree.Literal{
+								Value: 3,
+							},
+							Operator: token.Token{
+								Type:     token.DOUBLE_STAR,
+								Text:     "**",
+								Literal:  "**",
+								Position: 6,
+								Line:     1,
+								Column:   7,
+							},
+							Right: tree.Literal{
+								Value: 2,
+							},
+						},
+					},
+				},
+			},
+		},
+		"bitwise: and binds tighter than comparison": {
+			InputString: "a & 1 == 0",
+			Statements: []tree.Stmt{
+				tree.ExpressionStmt{
+					Expr: tree.Binary{
+						Left: tree.Binary{
+							Left: tree.Variable{
+								Name: token.Token{
+									Type:     token.IDENTIFIER,
+									Text:     "a",
+									Literal:  "a",
+									Position: 0,
+									Line:     1,
+									Column:   1,
+								},
+							},
+							Operator: token.Token{
+								Type:     token.AMPERSAND,
+								Text:     "&",
+								Literal:  "&",
+								Position: 2,
+								Line:     1,
+								Column:   3,
+							},
+							Right: tree.Literal{
+								Value: 1,
+							},
+						},
+						Operator: token.Token{
+							Type:     token.DOUBLE_EQUAL,
+							Text:     "==",
+							Literal:  "==",
+							Position: 6,
+							Line:     1,
+							Column:   7,
+						},
+						Right: tree.Literal{
+							Value: 0,
+						},
+					},
+				},
+			},
+		},
+		"shift: term binds tighter than shift": {
+			InputString: "1 << 2 + 3",
+			Statements: []tree.Stmt{
+				tree.ExpressionStmt{
+					Expr: tree.Binary{
+						Left: tree.Literal{
+							Value: 1,
+						},
+						Operator: token.Token{
+							Type:     token.SHIFT_LEFT,
+							Text:     "<<",
+							Literal:  "<<",
+							Position: 2,
+							Line:     1,
+							Column:   3,
+						},
+						Right: tree.Binary{
+							Left: tree.Literal{
+								Value: 2,
+							},
+							Operator: token.Token{
+								Type:     token.PLUS,
+								Text:     "+",
+								Literal:  "+",
+								Position: 7,
+								Line:     1,
+								Column:   8,
+							},
+							Right: tree.Literal{
+								Value: 3,
+							},
+						},
+					},
+				},
+			},
+		},
+		"unary: plus": {
+			InputString: "+2",
+			Statements: []tree.Stmt{
+				tree.ExpressionStmt{
+					Expr: tree.Unary{
+						Operator: token.Token{
+							Type:     token.PLUS,
+							Text:     "+",
+							Literal:  "+",
+							Position: 0,
+							Line:     1,
+							Column:   1,
+						},
+						Right: tree.Literal{
+							Value: 2,
+						},
+					},
+				},
+			},
+		},
+		"unary: nested": {
+			InputString: "!-2",
+			Statements: []tree.Stmt{
+				tree.ExpressionStmt{
+					Expr: tree.Unary{
+						Operator: token.Token{
+							Type:     token.BANG,
+							Text:     "!",
+							Literal:  "!",
+							Position: 0,
+							Line:     1,
+							Column:   1,
+						},
+						Right: tree.Unary{
+							Operator: token.Token{
+								Type:     token.MINUS,
+								Text:     "-",
+								Literal:  "-",
+								Position: 1,
+								Line:     1,
+								Column:   2,
+							},
+							Right: tree.Literal{
+								Value: 2,
+							},
+						},
+					},
+				},
+			},
+		},
 		"primary: identifier": {
 			InputString: "myVariable",
 			Statements: []tree.Stmt{
@@ -134,6 +471,7 @@ func TestExpressions(t *testing.T) {
 							Literal:  "myVariable",
 							Position: 0,
 							Line:     1,
+							Column:   1,
 						},
 					},
 				},
@@ -146,7 +484,10 @@ func TestExpressions(t *testing.T) {
 			l := lexer.New(testcase.InputString)
 			p := parser.New(l.Tokens)
 			parsedExpression := p.Parse()
-			if !reflect.DeepEqual(testcase.Statements, parsedExpression) {
+			if len(p.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", p.Errors)
+			}
+			if !reflect.DeepEqual(testcase.Statements, withoutSpans(parsedExpression)) {
 				t.Fatalf("expressions do not match: expected: %+v, actual: %+v", testcase.Statements, parsedExpression)
 			}
 		})
@@ -191,6 +532,7 @@ func TestStatements(t *testing.T) {
 								Literal:  "myVariable",
 								Position: 1,
 								Line:     1,
+								Column:   2,
 							},
 							Initializer: tree.ExpressionStmt{
 								Expr: tree.Literal{
@@ -215,8 +557,9 @@ func TestStatements(t *testing.T) {
 								Type:     token.IDENTIFIER,
 								Text:     "myVariable",
 								Literal:  "myVariable",
-								Position: 1,
-								Line:     1,
+								Position: 6,
+								Line:     2,
+								Column:   6,
 							},
 							Initializer: tree.ExpressionStmt{
 								Expr: tree.Literal{
@@ -231,8 +574,12 @@ func TestStatements(t *testing.T) {
 						tree.ExpressionStmt{
 							Expr: tree.Variable{
 								Name: token.Token{
-									Type:    token.IDENTIFIER,
-									Literal: "myVariable",
+									Type:     token.IDENTIFIER,
+									Text:     "myVariable",
+									Literal:  "myVariable",
+									Position: 39,
+									Line:     3,
+									Column:   6,
 								},
 							},
 						},
@@ -260,6 +607,7 @@ func TestStatements(t *testing.T) {
 									Literal:  "print",
 									Position: 10,
 									Line:     1,
+									Column:   11,
 								},
 							},
 							ClosingParen: token.Token{
@@ -268,6 +616,7 @@ func TestStatements(t *testing.T) {
 								Literal:  ")",
 								Position: 16,
 								Line:     1,
+								Column:   17,
 							},
 						},
 					},
@@ -301,6 +650,7 @@ func TestStatements(t *testing.T) {
 											Literal:  "join",
 											Position: 18,
 											Line:     1,
+											Column:   19,
 										},
 									},
 									ClosingParen: token.Token{
@@ -309,6 +659,7 @@ func TestStatements(t *testing.T) {
 										Literal:  ")",
 										Position: 26,
 										Line:     1,
+										Column:   27,
 									},
 									Arguments: []tree.Expr{
 										tree.Literal{
@@ -328,6 +679,7 @@ func TestStatements(t *testing.T) {
 									Literal:  "print",
 									Position: 29,
 									Line:     1,
+									Column:   30,
 								},
 							},
 							ClosingParen: token.Token{
@@ -336,6 +688,7 @@ func TestStatements(t *testing.T) {
 								Literal:  ")",
 								Position: 35,
 								Line:     1,
+								Column:   36,
 							},
 						},
 					},
@@ -354,6 +707,7 @@ func TestStatements(t *testing.T) {
 								Literal:  "helloName",
 								Position: 1,
 								Line:     1,
+								Column:   2,
 							},
 							Initializer: tree.FuncStmt{
 								Arguments: []tree.Stmt{
@@ -365,6 +719,7 @@ func TestStatements(t *testing.T) {
 												Literal:  "name",
 												Position: 13,
 												Line:     1,
+												Column:   14,
 											},
 										},
 									},
@@ -385,6 +740,7 @@ func TestStatements(t *testing.T) {
 														Literal:  "name",
 														Position: 34,
 														Line:     1,
+														Column:   35,
 													},
 												},
 											},
@@ -398,6 +754,7 @@ func TestStatements(t *testing.T) {
 														Literal:  "join",
 														Position: 40,
 														Line:     1,
+														Column:   41,
 													},
 												},
 												ClosingParen: token.Token{
@@ -406,6 +763,7 @@ func TestStatements(t *testing.T) {
 													Literal:  ")",
 													Position: 48,
 													Line:     1,
+													Column:   49,
 												},
 												Arguments: []tree.Expr{
 													tree.Literal{
@@ -421,6 +779,7 @@ func TestStatements(t *testing.T) {
 														Literal:  "print",
 														Position: 50,
 														Line:     1,
+														Column:   51,
 													},
 												},
 												ClosingParen: token.Token{
@@ -429,6 +788,7 @@ func TestStatements(t *testing.T) {
 													Literal:  ")",
 													Position: 56,
 													Line:     1,
+													Column:   57,
 												},
 											},
 										},
@@ -440,6 +800,40 @@ func TestStatements(t *testing.T) {
 				},
 			},
 		},
+		"conditional": {
+			InputString: "?((true) => 1, named: () => 2)",
+			Statements: []tree.Stmt{
+				tree.ConditionalStmt{
+					Branches: []tree.Branch{
+						{
+							Condition: tree.Literal{
+								Value: true,
+							},
+							Action: tree.ExpressionStmt{
+								Expr: tree.Literal{
+									Value: 1,
+								},
+							},
+						},
+						{
+							Name: token.Token{
+								Type:     token.IDENTIFIER,
+								Text:     "named",
+								Literal:  "named",
+								Position: 15,
+								Line:     1,
+								Column:   16,
+							},
+							Action: tree.ExpressionStmt{
+								Expr: tree.Literal{
+									Value: 2,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		"user defined function with return statement": {
 			InputString: "(helloName: (name) => { >> \"hello\" + name })",
 			Statements: []tree.Stmt{
@@ -452,6 +846,7 @@ func TestStatements(t *testing.T) {
 								Literal:  "helloName",
 								Position: 1,
 								Line:     1,
+								Column:   2,
 							},
 							Initializer: tree.FuncStmt{
 								Arguments: []tree.Stmt{
@@ -463,6 +858,7 @@ func TestStatements(t *testing.T) {
 												Literal:  "name",
 												Position: 13,
 												Line:     1,
+												Column:   14,
 											},
 										},
 									},
@@ -475,6 +871,7 @@ func TestStatements(t *testing.T) {
 											Literal:  ">>",
 											Position: 24,
 											Line:     1,
+											Column:   25,
 										},
 										Value: tree.ExpressionStmt{
 											Expr: tree.Binary{
@@ -485,6 +882,7 @@ func TestStatements(t *testing.T) {
 													Literal:  "+",
 													Position: 35,
 													Line:     1,
+													Column:   36,
 												},
 												Right: tree.Variable{
 													Name: token.Token{
@@ -493,6 +891,7 @@ func TestStatements(t *testing.T) {
 														Literal:  "name",
 														Position: 37,
 														Line:     1,
+														Column:   38,
 													},
 												},
 											},
@@ -512,9 +911,214 @@ func TestStatements(t *testing.T) {
 			l := lexer.New(testcase.InputString)
 			p := parser.New(l.Tokens)
 			parsedExpression := p.Parse()
-			if !reflect.DeepEqual(testcase.Statements, parsedExpression) {
+			if len(p.Errors) > 0 {
+				t.Fatalf("unexpected errors: %v", p.Errors)
+			}
+			if !reflect.DeepEqual(testcase.Statements, withoutSpans(parsedExpression)) {
 				t.Fatalf("expressions do not match: expected: %+v, actual: %+v", testcase.Statements, parsedExpression)
 			}
 		})
 	}
 }
+
+func TestErrors(t *testing.T) {
+	cases := map[string]struct {
+		InputString string
+		Errors      []string
+		Codes       []errors.Code
+	}{
+		"one per list item": {
+			InputString: "(\n  a: 1 +,\n  b: (2, * 3),\n  c: ?((a ==) => 1),\n  f: (x, 1) => { >> x },\n  g: (y) => { y = }\n)",
+			Errors: []string{
+				"[line 2] Error at ',': expect expression.\n",
+				"[line 3] Error at '*': expect expression.\n",
+				"[line 4] Error at ')': expect expression.\n",
+				"[line 5] Error at '{': function arguments must be identifiers\n",
+				"[line 6] Error at '}': expect expression.\n",
+			},
+			Codes: []errors.Code{
+				errors.ExpectedExpression,
+				errors.ExpectedExpression,
+				errors.ExpectedExpression,
+				errors.InvalidArgumentName,
+				errors.ExpectedExpression,
+			},
+		},
+		"one per statement": {
+			InputString: "a: /\nb: 1\nc: )\nd: *",
+			Errors: []string{
+				"[line 1] Error at '/': expect expression.\n",
+				"[line 3] Error at ')': expect expression.\n",
+				"[line 4] Error at '*': expect expression.\n",
+			},
+			Codes: []errors.Code{errors.ExpectedExpression, errors.ExpectedExpression, errors.ExpectedExpression},
+		},
+		"not repeated for lexer errors": {
+			InputString: "(b: @)\n(a: 1 @ 2)\n(c: \"hello)",
+		},
+		"not repeated for an unterminated interpolation": {
+			InputString: "(\"total: ${total)",
+		},
+		"not repeated for an unterminated comment": {
+			InputString: "(a: 1) /* a\n(b: 2)",
+		},
+		"unclosed list after a lexer error": {
+			InputString: "(a: @\n",
+			Errors: []string{
+				"[line 2] Error at end: expected ')' after expression\n",
+			},
+			Codes: []errors.Code{errors.ExpectedToken},
+		},
+		"unclosed list": {
+			InputString: "(1, 2",
+			Errors: []string{
+				"[line 1] Error at end: expected ')' after expression\n",
+			},
+			Codes: []errors.Code{errors.ExpectedToken},
+		},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := lexer.New(testcase.InputString)
+			p := parser.New(l.Tokens)
+			p.Parse()
+
+			var messages []string
+			var codes []errors.Code
+			for _, err := range p.Errors {
+				messages = append(messages, err.Error())
+				codes = append(codes, err.Code)
+			}
+			if !reflect.DeepEqual(testcase.Errors, messages) {
+				t.Fatalf("errors do not match: expected: %q, actual: %q", testcase.Errors, messages)
+			}
+			if !reflect.DeepEqual(testcase.Codes, codes) {
+				t.Fatalf("codes do not match: expected: %q, actual: %q", testcase.Codes, codes)
+			}
+		})
+	}
+}
+
+func TestRecoveredStatements(t *testing.T) {
+	l := lexer.New("a: /\nb: 1")
+	p := parser.New(l.Tokens)
+	statements := p.Parse()
+
+	// the statement after the error is still parsed
+	expected := []tree.Stmt{
+		tree.VariableStmt{
+			Name:        token.Token{Type: token.IDENTIFIER, Text: "b", Literal: "b", Position: 5, Line: 2, Column: 1},
+			Initializer: tree.ExpressionStmt{Expr: tree.Literal{Value: 1}},
+		},
+	}
+	if !reflect.DeepEqual(expected, withoutSpans(statements)) {
+		t.Fatalf("statements do not match: expected: %+v, actual: %+v", expected, statements)
+	}
+}
+
+func TestParse(t *testing.T) {
+	statements, err := parser.Parse(lexer.New("(1, 2)").Tokens)
+	if err != nil || len(statements) != 1 {
+		t.Fatalf("expected one statement and no error, got: %+v, %v", statements, err)
+	}
+
+	_, err = parser.Parse(lexer.New("a: /\nb: *").Tokens)
+	var list parser.ErrorList
+	if !stderrors.As(err, &list) || len(list) != 2 {
+		t.Fatalf("expected a list of 2 errors, got: %v", err)
+	}
+	var parseErr *parser.ParseError
+	if !stderrors.As(err, &parseErr) || parseErr.Token.Text != "/" {
+		t.Fatalf("expected the first error to be at '/', got: %v", err)
+	}
+}
+
+func TestSpans(t *testing.T) {
+	p := parser.New(lexer.New("(a: 1 + 2,\n  b: \"x ${a}\")").Tokens)
+	p.File = "spans.tim"
+	statements := p.Parse()
+
+	span := func(startLine, startColumn, endLine, endColumn int) [4]int {
+		return [4]int{startLine, startColumn, endLine, endColumn}
+	}
+	positions := func(s token.Span) [4]int {
+		if s.File != "spans.tim" {
+			t.Fatalf("expected span to be in spans.tim, got: %s", s.File)
+		}
+		return span(s.Start.Line, s.Start.Column, s.End.Line, s.End.Column)
+	}
+
+	list := statements[0].(tree.ListStmt)
+	a := list.Items[0].(tree.VariableStmt)
+	binary := a.Initializer.(tree.ExpressionStmt).Expr.(tree.Binary)
+	b := list.Items[1].(tree.VariableStmt)
+	interpolation := b.Initializer.(tree.ExpressionStmt).Expr.(tree.Interpolation)
+
+	expected := [][4]int{
+		span(1, 1, 2, 15),  // the whole list
+		span(1, 2, 1, 10),  // a: 1 + 2
+		span(1, 5, 1, 10),  // 1 + 2
+		span(1, 9, 1, 10),  // 2
+		span(2, 3, 2, 14),  // b: "x ${a}"
+		span(2, 6, 2, 14),  // "x ${a}"
+		span(2, 11, 2, 12), // a
+	}
+	actual := [][4]int{
+		positions(list.Span),
+		positions(a.Span),
+		positions(binary.Span),
+		positions(binary.Right.(tree.Literal).Span),
+		positions(b.Span),
+		positions(interpolation.Span),
+		positions(interpolation.Parts[1].(tree.Variable).Span),
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("spans do not match: expected: %v, actual: %v", expected, actual)
+	}
+}
+
+var (
+	spanType = reflect.TypeOf(token.Span{})
+	posType  = reflect.TypeOf(token.Pos{})
+)
+
+// the expected statements in these tests leave out positions, other than those of tokens
+// which they've always had, so spans and token ends are cleared before comparing
+func withoutSpans(statements []tree.Stmt) []tree.Stmt {
+	return clearSpans(reflect.ValueOf(statements)).Interface().([]tree.Stmt)
+}
+
+func clearSpans(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		cleared := reflect.New(value.Type()).Elem()
+		cleared.Set(clearSpans(value.Elem()))
+		return cleared
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		cleared := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for index := 0; index < value.Len(); index++ {
+			cleared.Index(index).Set(clearSpans(value.Index(index)))
+		}
+		return cleared
+	case reflect.Struct:
+		if value.Type() == spanType || value.Type() == posType {
+			return reflect.Zero(value.Type())
+		}
+		cleared := reflect.New(value.Type()).Elem()
+		cleared.Set(value)
+		for index := 0; index < value.NumField(); index++ {
+			if cleared.Field(index).CanSet() {
+				cleared.Field(index).Set(clearSpans(value.Field(index)))
+			}
+		}
+		return cleared
+	}
+	return value
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	stderrors "errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"tim/diagnostics"
+	"tim/errors"
+	"tim/interpreter"
+	"tim/lexer"
+	"tim/parser"
+	"tim/token"
+	"tim/tree"
+)
+
+const (
+	prompt             = "> "
+	continuationPrompt = ". "
+)
+
+func replCommand(args []string) int {
+	flags := flag.NewFlagSet("repl", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	strict := strictFlag(flags)
+	color := colorFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 0 {
+		fmt.Fprintf(os.Stderr, "tim repl: unexpected arguments\n\n%s", usage)
+		return exitUsage
+	}
+
+	repl(os.Stdin, os.Stdout, os.Stderr, *color, *strict)
+	return 0
+}
+
+// repl reads entries line by line and executes them against a single interpreter,
+// so variables defined by one entry can be used by the next. Each entry is named
+// <repl:N> and its text is kept, so that errors in code from an earlier entry, such
+// as the body of a function it defined, are shown against the right source.
+func repl(in io.Reader, out io.Writer, errOut io.Writer, color bool, strict bool) {
+	i := interpreter.New()
+	i.Strict = strict
+	scanner := bufio.NewScanner(in)
+
+	var input strings.Builder
+	entries := make(map[string]string)
+	fmt.Fprint(out, prompt)
+	for scanner.Scan() {
+		input.WriteString(scanner.Text())
+		input.WriteString("\n")
+
+		entry := fmt.Sprintf("<repl:%d>", len(entries)+1)
+		statements, errs := compile(entry, input.String())
+		if isIncomplete(errs) {
+			fmt.Fprint(out, continuationPrompt)
+			continue
+		}
+		entries[entry] = input.String()
+		input.Reset()
+
+		if len(errs) > 0 {
+			for _, err := range errs {
+				printREPLError(errOut, err, entries, entry, color)
+			}
+		} else {
+			for _, statement := range statements {
+				// statements are run one at a time so that each value is echoed straight after
+				// anything the statement printed
+				values, err := i.Interpret([]tree.Stmt{statement})
+				if err != nil {
+					printREPLError(errOut, err, entries, entry, color)
+					break
+				}
+				if values[0] != nil {
+					fmt.Fprintln(out, interpreter.PrintValue(values[0]))
+				}
+			}
+		}
+		fmt.Fprint(out, prompt)
+	}
+	fmt.Fprintln(out)
+}
+
+// renders the error against the entry its span belongs to. Spans without a file,
+// such as those of lexer errors, belong to the current entry.
+func printREPLError(w io.Writer, err error, entries map[string]string, current string, color bool) {
+	file := current
+	var diagnosable diagnostics.Error
+	if stderrors.As(err, &diagnosable) && diagnosable.Diagnostic().Span.File != "" {
+		file = diagnosable.Diagnostic().Span.File
+	}
+	printer := &diagnostics.Printer{
+		File:   current,
+		Source: entries[file],
+		Color:  color,
+	}
+	printer.Print(w, err)
+}
+
+func compile(file string, source string) ([]tree.Stmt, []error) {
+	tokens, lexErrs := lex(source)
+	statements, parseErrs := parse(file, tokens)
+	if errs := append(lexErrs, parseErrs...); len(errs) > 0 {
+		return nil, errs
+	}
+	return resolve(statements)
+}
+
+// input is incomplete when the only problem is that it ended too soon, e.g. an unclosed
+// '(' or '{', or a string or block comment that carries on over the next line
+func isIncomplete(errs []error) bool {
+	if len(errs) == 0 {
+		return false
+	}
+	for _, err := range errs {
+		var parseErr *parser.ParseError
+		var lexErr *lexer.LexError
+		switch {
+		case stderrors.As(err, &parseErr):
+			if parseErr.Token.Type != token.EOF {
+				return false
+			}
+		case stderrors.As(err, &lexErr):
+			if !endsEarly(lexErr.Code) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// the lexer errors that mean the input ran out before something was closed
+func endsEarly(code errors.Code) bool {
+	switch code {
+	case errors.UnterminatedString, errors.UnterminatedInterpolation, errors.UnterminatedComment:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestREPL(t *testing.T) {
+	cases := map[string]struct {
+		Input  string
+		StdOut string
+	}{
+		"state is kept between entries": {
+			Input:  "(x: 5)\n(x).print()\n",
+			StdOut: "> (5)\n> (5)\n> \n",
+		},
+		"continuation prompt for an unfinished block": {
+			Input:  "(f: (a) => {\n>> a\n})\n(7).call(f)\n",
+			StdOut: "> . . (\"<closure>\")\n> 7\n> \n",
+		},
+		"continuation prompt for an unterminated string": {
+			Input:  "(\"a\nb\").print()\n",
+			StdOut: "> . (\"a\nb\")\n> \n",
+		},
+		"continuation prompt for an unterminated comment": {
+			Input:  "/* a\nb */ (1)\n",
+			StdOut: "> . (1)\n> \n",
+		},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			var errOut strings.Builder
+			// print writes to stdout, so the prompts are written there too to keep them in order
+			out := captureStdOut(func() {
+				repl(strings.NewReader(testcase.Input), os.Stdout, &errOut, false, false)
+			})
+
+			assert.Equal(t, testcase.StdOut, out)
+			assert.Empty(t, errOut.String())
+		})
+	}
+}
+
+func TestREPLErrorsShowTheEntryTheyCameFrom(t *testing.T) {
+	var out, errOut strings.Builder
+	repl(strings.NewReader("(f: (x) => { >> x / 0 })\n(1).call(f)\n"), &out, &errOut, false, false)
+
+	assert.Equal(t, `runtime error[T2003]: division by zero
+ --> <repl:1>:1:17
+  |
+1 | (f: (x) => { >> x / 0 })
+  |                 ^^^^^
+`, errOut.String())
+}
+
+func TestABadCharacterIsReportedOnce(t *testing.T) {
+	_, errs := compile("<repl:1>", "(b: @)")
+
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "[line 1] Error: unsupported character '@'")
+}
+
+func captureStdOut(f func()) string {
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	outC := make(chan string)
+	// copy the output in a separate goroutine so printing can't block indefinitely
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		outC <- buf.String()
+	}()
+	f()
+	w.Close()
+	os.Stdout = old
+	return <-outC
+}
@@ -0,0 +1,316 @@
+package resolver
+
+import (
+	"fmt"
+	"tim/diagnostics"
+	"tim/errors"
+	"tim/token"
+	"tim/tree"
+)
+
+// Resolve walks the statements between parsing and interpreting, and records on each
+// variable how many scopes up it was declared. The returned statements carry the
+// recorded scopes; the originals are left untouched.
+func Resolve(statements []tree.Stmt) ([]tree.Stmt, []*ResolveError) {
+	r := &Resolver{}
+	return r.resolveStatements(statements), r.Errors
+}
+
+// Resolver mirrors the environments the interpreter creates: nested lists and function
+// calls get their own scope, while top level lists declare into the global scope, which
+// isn't tracked here because globals are looked up by name.
+type Resolver struct {
+	Scopes []map[string]bool
+	Errors []*ResolveError
+	// how many lists deep we are, counted from the top level or the start of a function body
+	level int
+	// how many functions deep we are, so that returns outside of a function can be reported
+	functionDepth int
+}
+
+func (r *Resolver) VisitExpressionStmt(stmt tree.ExpressionStmt) interface{} {
+	return tree.ExpressionStmt{
+		Expr: r.resolveExpr(stmt.Expr),
+		Span: stmt.Span,
+	}
+}
+
+func (r *Resolver) VisitVariableStmt(stmt tree.VariableStmt) interface{} {
+	r.declare(stmt.Name)
+
+	// functions are defined before their body is resolved so that they can call themselves
+	if _, ok := stmt.Initializer.(tree.FuncStmt); ok {
+		r.define(stmt.Name)
+	}
+
+	var initializer tree.Stmt
+	if stmt.Initializer != nil {
+		initializer = r.resolveStmt(stmt.Initializer)
+	}
+
+	r.define(stmt.Name)
+
+	return tree.VariableStmt{
+		Name:        stmt.Name,
+		Initializer: initializer,
+		Span:        stmt.Span,
+	}
+}
+
+func (r *Resolver) VisitListStmt(stmt tree.ListStmt) interface{} {
+	r.level++
+	defer func() {
+		r.level--
+	}()
+	if r.level > 1 {
+		r.beginScope()
+		defer r.endScope()
+	}
+
+	items := r.resolveStatements(stmt.Items)
+
+	var functions []tree.CallStmt
+	for _, function := range stmt.Functions {
+		functions = append(functions, r.resolveCall(function))
+	}
+
+	return tree.ListStmt{
+		Items:     items,
+		Functions: functions,
+		Span:      stmt.Span,
+	}
+}
+
+func (r *Resolver) resolveCall(stmt tree.CallStmt) tree.CallStmt {
+	var arguments []tree.Expr
+	for _, argument := range stmt.Arguments {
+		arguments = append(arguments, r.resolveExpr(argument))
+	}
+
+	return tree.CallStmt{
+		Callee:       r.resolveExpr(stmt.Callee),
+		ClosingParen: stmt.ClosingParen,
+		Arguments:    arguments,
+		Span:         stmt.Span,
+	}
+}
+
+func (r *Resolver) VisitFunctionStmt(stmt tree.FuncStmt) interface{} {
+	previousLevel := r.level
+	r.level = 0
+	r.functionDepth++
+	r.beginScope()
+	defer func() {
+		r.endScope()
+		r.functionDepth--
+		r.level = previousLevel
+	}()
+
+	for _, argument := range stmt.Arguments {
+		name := argument.(tree.ExpressionStmt).Expr.(tree.Variable).Name
+		r.declare(name)
+		r.define(name)
+	}
+
+	return tree.FuncStmt{
+		Body:      r.resolveStatements(stmt.Body),
+		Arguments: stmt.Arguments,
+		Span:      stmt.Span,
+	}
+}
+
+func (r *Resolver) VisitReturnStmt(stmt tree.ReturnStmt) interface{} {
+	if r.functionDepth == 0 {
+		r.error(stmt.Token, errors.ReturnOutsideFunction, "can't return from top-level code")
+	}
+
+	var value tree.Stmt
+	if stmt.Value != nil {
+		value = r.resolveStmt(stmt.Value)
+	}
+
+	return tree.ReturnStmt{
+		Token: stmt.Token,
+		Value: value,
+		Span:  stmt.Span,
+	}
+}
+
+func (r *Resolver) VisitConditionalStmt(stmt tree.ConditionalStmt) interface{} {
+	var branches []tree.Branch
+	for _, branch := range stmt.Branches {
+		var condition tree.Expr
+		if branch.Condition != nil {
+			condition = r.resolveExpr(branch.Condition)
+		}
+		branches = append(branches, tree.Branch{
+			Name:      branch.Name,
+			Condition: condition,
+			Action:    r.resolveStmt(branch.Action),
+			Span:      branch.Span,
+		})
+	}
+
+	return tree.ConditionalStmt{
+		Branches: branches,
+		Span:     stmt.Span,
+	}
+}
+
+func (r *Resolver) VisitAssignExpr(expr tree.Assign) interface{} {
+	return tree.Assign{
+		Name:  expr.Name,
+		Value: r.resolveExpr(expr.Value),
+		Scope: r.resolveLocal(expr.Name),
+		Span:  expr.Span,
+	}
+}
+
+func (r *Resolver) VisitBinaryExpr(expr tree.Binary) interface{} {
+	return tree.Binary{
+		Left:     r.resolveExpr(expr.Left),
+		Operator: expr.Operator,
+		Right:    r.resolveExpr(expr.Right),
+		Span:     expr.Span,
+	}
+}
+
+func (r *Resolver) VisitGroupingExpr(expr tree.Grouping) interface{} {
+	return tree.Grouping{
+		Expression: r.resolveExpr(expr.Expression),
+		Span:       expr.Span,
+	}
+}
+
+func (r *Resolver) VisitInterpolationExpr(expr tree.Interpolation) interface{} {
+	var parts []tree.Expr
+	for _, part := range expr.Parts {
+		parts = append(parts, r.resolveExpr(part))
+	}
+
+	return tree.Interpolation{
+		Parts: parts,
+		Span:  expr.Span,
+	}
+}
+
+func (r *Resolver) VisitLiteralExpr(expr tree.Literal) interface{} {
+	return expr
+}
+
+func (r *Resolver) VisitLogicalExpr(expr tree.Logical) interface{} {
+	return tree.Logical{
+		Left:     r.resolveExpr(expr.Left),
+		Operator: expr.Operator,
+		Right:    r.resolveExpr(expr.Right),
+		Span:     expr.Span,
+	}
+}
+
+func (r *Resolver) VisitUnaryExpr(expr tree.Unary) interface{} {
+	return tree.Unary{
+		Operator: expr.Operator,
+		Right:    r.resolveExpr(expr.Right),
+		Span:     expr.Span,
+	}
+}
+
+func (r *Resolver) VisitVariableExpr(expr tree.Variable) interface{} {
+	scope := r.resolveLocal(expr.Name)
+	if scope.Local && !r.Scopes[len(r.Scopes)-1-scope.Depth][expr.Name.Text] {
+		r.error(expr.Name, errors.ReadInOwnInitializer, "can't read local variable in its own initializer")
+	}
+
+	return tree.Variable{
+		Name:  expr.Name,
+		Scope: scope,
+		Span:  expr.Span,
+	}
+}
+
+func (r *Resolver) resolveStatements(statements []tree.Stmt) []tree.Stmt {
+	resolved := make([]tree.Stmt, 0, len(statements))
+	for _, stmt := range statements {
+		resolved = append(resolved, r.resolveStmt(stmt))
+	}
+	return resolved
+}
+
+func (r *Resolver) resolveStmt(stmt tree.Stmt) tree.Stmt {
+	return stmt.Accept(r).(tree.Stmt)
+}
+
+func (r *Resolver) resolveExpr(expr tree.Expr) tree.Expr {
+	return expr.Accept(r).(tree.Expr)
+}
+
+// find the innermost scope that declares the name, anything not found is global
+func (r *Resolver) resolveLocal(name token.Token) tree.Scope {
+	for index := len(r.Scopes) - 1; index >= 0; index-- {
+		if _, ok := r.Scopes[index][name.Text]; ok {
+			return tree.Scope{
+				Local: true,
+				Depth: len(r.Scopes) - 1 - index,
+			}
+		}
+	}
+	return tree.Scope{}
+}
+
+func (r *Resolver) beginScope() {
+	r.Scopes = append(r.Scopes, make(map[string]bool))
+}
+
+func (r *Resolver) endScope() {
+	r.Scopes = r.Scopes[:len(r.Scopes)-1]
+}
+
+func (r *Resolver) declare(name token.Token) {
+	if len(r.Scopes) == 0 {
+		return
+	}
+	r.Scopes[len(r.Scopes)-1][name.Text] = false
+}
+
+func (r *Resolver) define(name token.Token) {
+	if len(r.Scopes) == 0 {
+		return
+	}
+	r.Scopes[len(r.Scopes)-1][name.Text] = true
+}
+
+func (r *Resolver) error(thisToken token.Token, code errors.Code, message string) {
+	r.Errors = append(r.Errors, &ResolveError{
+		Code:    code,
+		Message: message,
+		Token:   thisToken,
+	})
+}
+
+var _ errors.Error = (*ResolveError)(nil)
+
+type ResolveError struct {
+	Code    errors.Code
+	Message string
+	Token   token.Token
+}
+
+func (re *ResolveError) Error() string {
+	return fmt.Sprintf("[line %d] Error at '%s': %s\n", re.Token.Line, re.Token.Text, re.Message)
+}
+
+func (re *ResolveError) ErrorCode() errors.Code {
+	return re.Code
+}
+
+func (re *ResolveError) Diagnostic() diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Kind:    "resolve error",
+		Code:    string(re.Code),
+		Message: re.Message,
+		Span: token.Span{
+			Start: re.Token.Start(),
+			End:   re.Token.End,
+		},
+	}
+}
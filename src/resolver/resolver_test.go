@@ -0,0 +1,81 @@
+package resolver_test
+
+import (
+	"testing"
+	"tim/errors"
+	"tim/lexer"
+	"tim/parser"
+	"tim/resolver"
+	"tim/tree"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ResolveCase struct {
+	InputString string
+	Errors      []string
+	Codes       []errors.Code
+}
+
+func TestResolveErrors(t *testing.T) {
+	cases := map[string]ResolveCase{
+		"no errors": {
+			InputString: "(add: (x, y) => { >> x + y })\n(1, 2).call(add).print()",
+		},
+		"recursive function": {
+			InputString: "(fib: (n) => { >> (n - 1).call(fib) })",
+		},
+		"read local variable in its own initializer": {
+			InputString: "(f: () => { (x: x + 1) })",
+			Errors: []string{
+				"[line 1] Error at 'x': can't read local variable in its own initializer\n",
+			},
+			Codes: []errors.Code{errors.ReadInOwnInitializer},
+		},
+		"read local variable in its own nested initializer": {
+			InputString: "((x: (x, 1)))",
+			Errors: []string{
+				"[line 1] Error at 'x': can't read local variable in its own initializer\n",
+			},
+			Codes: []errors.Code{errors.ReadInOwnInitializer},
+		},
+		"return outside function": {
+			InputString: "(>> 5)\n(>> 6)",
+			Errors: []string{
+				"[line 1] Error at '>>': can't return from top-level code\n",
+				"[line 2] Error at '>>': can't return from top-level code\n",
+			},
+			Codes: []errors.Code{errors.ReturnOutsideFunction, errors.ReturnOutsideFunction},
+		},
+	}
+
+	for name, testcase := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := lexer.New(testcase.InputString)
+			p := parser.New(l.Tokens)
+			_, errs := resolver.Resolve(p.Parse())
+
+			var messages []string
+			var codes []errors.Code
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+				codes = append(codes, err.Code)
+			}
+			assert.Equal(t, testcase.Errors, messages)
+			assert.Equal(t, testcase.Codes, codes)
+		})
+	}
+}
+
+func TestResolveDepth(t *testing.T) {
+	l := lexer.New("(outer: (x) => { (inner: () => { >> x }) })")
+	p := parser.New(l.Tokens)
+	resolved, errs := resolver.Resolve(p.Parse())
+	assert.Empty(t, errs)
+
+	outer := resolved[0].(tree.ListStmt).Items[0].(tree.VariableStmt).Initializer.(tree.FuncStmt)
+	inner := outer.Body[0].(tree.ListStmt).Items[0].(tree.VariableStmt).Initializer.(tree.FuncStmt)
+	variable := inner.Body[0].(tree.ReturnStmt).Value.(tree.ExpressionStmt).Expr.(tree.Variable)
+
+	assert.Equal(t, tree.Scope{Local: true, Depth: 1}, variable.Scope)
+}
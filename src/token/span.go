@@ -0,0 +1,31 @@
+package token
+
+import "fmt"
+
+// Pos is a place in the source. Offset is in bytes, while Line and Column count from 1,
+// with columns counted in runes.
+type Pos struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// Span is the part of a file's source that something was read from, from the first
+// character at Start up to End, which is just past the last character
+type Span struct {
+	File  string
+	Start Pos
+	End   Pos
+}
+
+// IsValid reports whether the span has been set, the zero Span is used when there's no source to point to
+func (s Span) IsValid() bool {
+	return s.Start.Line > 0
+}
+
+func (s Span) String() string {
+	if s.File == "" {
+		return fmt.Sprintf("%d:%d", s.Start.Line, s.Start.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", s.File, s.Start.Line, s.Start.Column)
+}
@@ -13,6 +13,11 @@ const (
 	MINUS
 	STAR
 	SLASH
+	PERCENT
+	AMPERSAND
+	PIPE
+	CARET
+	TILDE
 	QUESTION
 	SEMICOLON
 
@@ -26,13 +31,20 @@ const (
 	LESS
 	GREATER_EQUAL
 	LESS_EQUAL
-	INCREMENT // ++
-	DECREMENT // --
-	RETURN    // >>
+	INCREMENT   // ++
+	DECREMENT   // --
+	RETURN      // >>
+	DOUBLE_STAR // **
+	TILDE_SLASH // ~/, floor division
+	SHIFT_LEFT  // <<
+	SHIFT_RIGHT // >>>, as >> is taken by RETURN
+	AND         // &&
+	OR          // ||
 
 	// literals
 	IDENTIFIER
 	STRING
+	INTERPOLATION // the part of a string before ${
 	NUMBER
 
 	// keywords
@@ -42,6 +54,9 @@ const (
 
 	NEWLINE
 	EOF
+
+	// input the lexer couldn't scan and has already reported, e.g. an unsupported character
+	ILLEGAL
 )
 
 type TokenType int
@@ -66,6 +81,20 @@ func (tt TokenType) String() string {
 		return "PLUS"
 	case MINUS:
 		return "MINUS"
+	case STAR:
+		return "STAR"
+	case SLASH:
+		return "SLASH"
+	case PERCENT:
+		return "PERCENT"
+	case AMPERSAND:
+		return "AMPERSAND"
+	case PIPE:
+		return "PIPE"
+	case CARET:
+		return "CARET"
+	case TILDE:
+		return "TILDE"
 	case QUESTION:
 		return "QUESTION"
 	case SEMICOLON:
@@ -88,8 +117,22 @@ func (tt TokenType) String() string {
 		return "DECREMENT"
 	case RETURN:
 		return "RETURN"
+	case DOUBLE_STAR:
+		return "DOUBLE_STAR"
+	case TILDE_SLASH:
+		return "TILDE_SLASH"
+	case SHIFT_LEFT:
+		return "SHIFT_LEFT"
+	case SHIFT_RIGHT:
+		return "SHIFT_RIGHT"
+	case AND:
+		return "AND"
+	case OR:
+		return "OR"
 	case STRING:
 		return "STRING"
+	case INTERPOLATION:
+		return "INTERPOLATION"
 	case NUMBER:
 		return "NUMBER"
 	case IDENTIFIER:
@@ -102,17 +145,32 @@ func (tt TokenType) String() string {
 		return "NEWLINE"
 	case EOF:
 		return "EOF"
+	case ILLEGAL:
+		return "ILLEGAL"
 	default:
 		return ""
 	}
 }
 
+// Position is the byte offset of the token in the source, while Line and Column count from 1,
+// with columns counted in runes. End is just past the last character of the token.
 type Token struct {
 	Type     TokenType
 	Text     string
 	Literal  interface{}
 	Position int
 	Line     int
+	Column   int
+	End      Pos
+}
+
+// Start is where the token begins
+func (t Token) Start() Pos {
+	return Pos{
+		Offset: t.Position,
+		Line:   t.Line,
+		Column: t.Column,
+	}
 }
 
 // temporary
@@ -131,16 +189,28 @@ func IsListFunction(name string) bool {
 	return false
 }
 
+var keywords = map[string]TokenType{
+	"true":  TRUE,
+	"false": FALSE,
+	"nil":   NIL,
+}
+
+// Keywords returns a copy of the keywords, so that callers can't change what the lexer treats as one
 func Keywords() map[string]TokenType {
-	return map[string]TokenType{
-		"true":  TRUE,
-		"false": FALSE,
-		"nil":   NIL,
+	copied := make(map[string]TokenType, len(keywords))
+	for text, tokenType := range keywords {
+		copied[text] = tokenType
 	}
+	return copied
+}
+
+func LookupKeyword(text string) (TokenType, bool) {
+	tokenType, ok := keywords[text]
+	return tokenType, ok
 }
 
 func IsKeyword(tt TokenType) bool {
-	for _, tokenType := range Keywords() {
+	for _, tokenType := range keywords {
 		if tt == tokenType {
 			return true
 		}
@@ -4,22 +4,71 @@ import (
 	"tim/token"
 )
 
+// Expr is an expression in the tree. Every node, expression or statement, has a Span
+// covering the source it was parsed from.
 type Expr interface {
 	Accept(visitor ExprVisitor) interface{}
 }
 
+// SpanOf returns the span of any expression, for errors about an expression's value
+// rather than the expression that failed to evaluate
+func SpanOf(expr Expr) token.Span {
+	switch e := expr.(type) {
+	case Assign:
+		return e.Span
+	case Binary:
+		return e.Span
+	case Grouping:
+		return e.Span
+	case Interpolation:
+		return e.Span
+	case Literal:
+		return e.Span
+	case Logical:
+		return e.Span
+	case Unary:
+		return e.Span
+	case Variable:
+		return e.Span
+	}
+	return token.Span{}
+}
+
 type ExprVisitor interface {
+	VisitAssignExpr(expr Assign) interface{}
 	VisitBinaryExpr(expr Binary) interface{}
 	VisitGroupingExpr(expr Grouping) interface{}
+	VisitInterpolationExpr(expr Interpolation) interface{}
 	VisitLiteralExpr(expr Literal) interface{}
+	VisitLogicalExpr(expr Logical) interface{}
 	VisitUnaryExpr(expr Unary) interface{}
 	VisitVariableExpr(expr Variable) interface{}
 }
 
+type Assign struct {
+	Name  token.Token
+	Value Expr
+	Scope Scope
+	Span  token.Span
+}
+
+func (a Assign) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitAssignExpr(a)
+}
+
+// Scope is recorded by the resolver for variables declared in a local scope.
+// Depth is how many environments up from the current one the variable lives.
+// The zero value is a global, which is looked up by name.
+type Scope struct {
+	Local bool
+	Depth int
+}
+
 type Binary struct {
 	Left     Expr
 	Operator token.Token
 	Right    Expr
+	Span     token.Span
 }
 
 func (b Binary) Accept(visitor ExprVisitor) interface{} {
@@ -28,23 +77,50 @@ func (b Binary) Accept(visitor ExprVisitor) interface{} {
 
 type Grouping struct {
 	Expression Expr
+	Span       token.Span
 }
 
 func (g Grouping) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitGroupingExpr(g)
 }
 
+// Interpolation is a string with embedded expressions, made of
+// its literal string parts and expressions in the order they appear
+type Interpolation struct {
+	Parts []Expr
+	Span  token.Span
+}
+
+func (i Interpolation) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitInterpolationExpr(i)
+}
+
 type Literal struct {
 	Value interface{}
+	Span  token.Span
 }
 
 func (l Literal) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitLiteralExpr(l)
 }
 
+// Logical is an && or ||, which are kept apart from Binary because the right side
+// is only evaluated when the left side doesn't decide the result
+type Logical struct {
+	Left     Expr
+	Operator token.Token
+	Right    Expr
+	Span     token.Span
+}
+
+func (l Logical) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitLogicalExpr(l)
+}
+
 type Unary struct {
 	Operator token.Token
 	Right    Expr
+	Span     token.Span
 }
 
 func (u Unary) Accept(visitor ExprVisitor) interface{} {
@@ -52,7 +128,9 @@ func (u Unary) Accept(visitor ExprVisitor) interface{} {
 }
 
 type Variable struct {
-	Name token.Token
+	Name  token.Token
+	Scope Scope
+	Span  token.Span
 }
 
 func (v Variable) Accept(visitor ExprVisitor) interface{} {
@@ -14,10 +14,12 @@ type StmtVisitor interface {
 	VisitListStmt(stmt ListStmt) interface{}
 	VisitFunctionStmt(stmt FuncStmt) interface{}
 	VisitReturnStmt(stmt ReturnStmt) interface{}
+	VisitConditionalStmt(stmt ConditionalStmt) interface{}
 }
 
 type ExpressionStmt struct {
 	Expr Expr
+	Span token.Span
 }
 
 func (es ExpressionStmt) Accept(visitor StmtVisitor) interface{} {
@@ -27,6 +29,7 @@ func (es ExpressionStmt) Accept(visitor StmtVisitor) interface{} {
 type VariableStmt struct {
 	Name        token.Token
 	Initializer Stmt
+	Span        token.Span
 }
 
 func (vs VariableStmt) Accept(visitor StmtVisitor) interface{} {
@@ -36,6 +39,7 @@ func (vs VariableStmt) Accept(visitor StmtVisitor) interface{} {
 type ListStmt struct {
 	Items     []Stmt
 	Functions []CallStmt
+	Span      token.Span
 }
 
 func (ls ListStmt) Accept(visitor StmtVisitor) interface{} {
@@ -46,6 +50,7 @@ type CallStmt struct {
 	Callee       Expr
 	ClosingParen token.Token
 	Arguments    []Expr
+	Span         token.Span
 }
 
 // i don't know what to do with this
@@ -56,6 +61,7 @@ func (cs CallStmt) Accept(visitor StmtVisitor) interface{} {
 type FuncStmt struct {
 	Body      []Stmt
 	Arguments []Stmt
+	Span      token.Span
 }
 
 func (fs FuncStmt) Accept(visitor StmtVisitor) interface{} {
@@ -65,8 +71,27 @@ func (fs FuncStmt) Accept(visitor StmtVisitor) interface{} {
 type ReturnStmt struct {
 	Token token.Token
 	Value Stmt
+	Span  token.Span
 }
 
 func (rs ReturnStmt) Accept(visitor StmtVisitor) interface{} {
 	return visitor.VisitReturnStmt(rs)
 }
+
+type ConditionalStmt struct {
+	Branches []Branch
+	Span     token.Span
+}
+
+func (cs ConditionalStmt) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitConditionalStmt(cs)
+}
+
+// Branch is one arm of a conditional. Name is only set for named branches
+// and Condition is nil for the else branch.
+type Branch struct {
+	Name      token.Token
+	Condition Expr
+	Action    Stmt
+	Span      token.Span
+}